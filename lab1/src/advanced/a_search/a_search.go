@@ -3,12 +3,11 @@ package a_search
 import (
 	"container/heap"
 	"fmt"
-	"src/board"
 )
 
-func A(start, terminal board.Board) ([]board.Board, int, bool) {
-	allNodes := nodeMap{}
-	openedList := &priorityQueue{}
+func A[T Pather[T]](start, terminal T) ([]T, int, bool) {
+	allNodes := nodeMap[T]{}
+	openedList := &priorityQueue[T]{}
 	heap.Init(openedList)
 
 	// Init OPENED list
@@ -23,13 +22,13 @@ func A(start, terminal board.Board) ([]board.Board, int, bool) {
 		}
 
 		// Close best node
-		current := heap.Pop(openedList).(*node)
+		current := heap.Pop(openedList).(*node[T])
 		current.opened = false
 		current.closed = true
 
 		// If found end -> trace back and return path
-		if current.pather.Board == allNodes.get(terminal).pather.Board {
-			var p []board.Board
+		if current.pather.Equals(terminal) {
+			var p []T
 			curr := current
 			for curr != nil {
 				p = append(p, curr.pather)
@@ -41,13 +40,15 @@ func A(start, terminal board.Board) ([]board.Board, int, bool) {
 
 		for _, neighbour := range current.pather.GetNeighbours() {
 
-			cost := current.cost + 1
+			cost := current.cost + current.pather.GetCost(neighbour)
 			neighborNode := allNodes.get(neighbour)
 			// If already in OPENED -> check if cost is lower
 			if cost < neighborNode.cost {
 				if neighborNode.opened {
 					heap.Remove(openedList, neighborNode.index)
 				}
+				neighborNode.opened = false
+				neighborNode.closed = false
 			}
 			// If completely new node -> add to OPENED
 			if !neighborNode.opened && !neighborNode.closed {
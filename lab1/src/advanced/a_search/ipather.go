@@ -1,7 +1,11 @@
 package a_search
 
-type Pather interface {
-	GetNeighbours() []Pather
-	GetCost(to Pather) int
-	Heuristic(to Pather) int
+// Pather is a state of the search space.
+// T is the state type itself, it is used as a key of nodeMap
+type Pather[T any] interface {
+	comparable
+	GetNeighbours() []T
+	GetCost(to T) int
+	Heuristic(to T) int
+	Equals(to T) bool
 }
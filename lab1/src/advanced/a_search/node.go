@@ -1,10 +1,8 @@
 package a_search
 
-import "src/board"
-
-type node struct {
-	pather board.Board
-	parent *node
+type node[T Pather[T]] struct {
+	pather T
+	parent *node[T]
 	cost   int
 	opened bool
 	closed bool
@@ -13,14 +11,14 @@ type node struct {
 }
 
 // Collection of nodes, indexed by their Pather component
-type nodeMap map[board.Board]*node
+type nodeMap[T Pather[T]] map[T]*node[T]
 
 // Method to get pather from collection
 // or add new element and return pointer
-func (nm nodeMap) get(p board.Board) *node {
+func (nm nodeMap[T]) get(p T) *node[T] {
 	n, ok := nm[p]
 	if !ok {
-		n = &node{
+		n = &node[T]{
 			pather: p,
 		}
 		nm[p] = n
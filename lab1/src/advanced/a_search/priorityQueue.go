@@ -1,29 +1,34 @@
 package a_search
 
-type priorityQueue []*node
+type priorityQueue[T Pather[T]] []*node[T]
 
-func (pq priorityQueue) Len() int {
+func (pq priorityQueue[T]) Len() int {
 	return len(pq)
 }
 
-func (pq priorityQueue) Less(i, j int) bool {
-	return pq[i].cost < pq[j].cost
+// Of nodes with equal rank the one with greater cost is closer to terminal
+func (pq priorityQueue[T]) Less(i, j int) bool {
+	if pq[i].rank == pq[j].rank {
+		return pq[i].cost > pq[j].cost
+	}
+
+	return pq[i].rank < pq[j].rank
 }
 
-func (pq priorityQueue) Swap(i, j int) {
+func (pq priorityQueue[T]) Swap(i, j int) {
 	pq[i], pq[j] = pq[j], pq[i]
 	pq[i].index = i
 	pq[j].index = j
 }
 
-func (pq *priorityQueue) Push(x interface{}) {
+func (pq *priorityQueue[T]) Push(x interface{}) {
 	n := len(*pq)
-	no := x.(*node)
+	no := x.(*node[T])
 	no.index = n
 	*pq = append(*pq, no)
 }
 
-func (pq *priorityQueue) Pop() interface{} {
+func (pq *priorityQueue[T]) Pop() interface{} {
 	old := *pq
 	n := len(old)
 	no := old[n-1]
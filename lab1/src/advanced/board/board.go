@@ -3,6 +3,7 @@ package board
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -16,49 +17,104 @@ const (
 	UNDEFINED rune = '4'
 )
 
+// Prices of checker moves
+type Costs struct {
+	Step  int // move to adjacent free cell
+	Jump  int // jump over a checker
+	Chain int // every next jump of multi-jump chain
+}
+
+var DefaultCosts = Costs{Step: 1, Jump: 1, Chain: 1}
+
+// Rules the board is played by
+type Rules struct {
+	MultiJump  bool // allow chains of jumps in one move
+	Diagonal   bool // allow diagonal steps and jumps
+	Admissible bool // price distance with the cheapest moves, so A* finds optimal path, but opens far more boards
+	Costs      Costs
+}
+
+var DefaultRules = &Rules{Costs: DefaultCosts}
+
 type Board struct {
 	size     int
 	Board    string
 	currMove rune
+	rules    *Rules
 }
 
-func NewBoardFromFile(path string) *Board {
+func NewBoardFromFile(path string) (*Board, error) {
 	csvConf, err := os.Open(path)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	defer func() {
-		if err := csvConf.Close(); err != nil {
-			panic(err)
-		}
-	}()
+	defer csvConf.Close()
 
-	reader := csv.NewReader(csvConf)
+	return ReadBoard(csvConf)
+}
+
+// Read board from CSV: size in the first line, then rows of pieces separated by spaces
+func ReadBoard(r io.Reader) (*Board, error) {
+	reader := csv.NewReader(r)
 	reader.Comma = ' '
 	reader.FieldsPerRecord = -1
-	data, err := reader.ReadAll()
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, &ParseError{Line: 1, Column: 1, Err: ErrSize}
+	}
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	line, column := reader.FieldPos(0)
+	size, err := strconv.Atoi(header[0])
+	if err != nil || len(header) != 1 || size < 1 {
+		return nil, &ParseError{Line: line, Column: column, Err: ErrSize}
 	}
 
-	var board Board
-	board.size, _ = strconv.Atoi(data[0][0])
-	data = data[1:]
+	board := Board{size: size, currMove: WHITE, rules: DefaultRules}
 	sb := strings.Builder{}
-	for _, row := range data {
-		for _, el := range row {
-			sb.WriteString(el)
+	for j := 0; ; j++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			if j != size {
+				return nil, &ParseError{Line: line + 1, Column: 1, Err: ErrRows}
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, column = reader.FieldPos(0)
+		// Skip trailing spaces
+		for len(row) > 1 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		if j == size {
+			return nil, &ParseError{Line: line, Column: column, Err: ErrRows}
+		}
+		if len(row) != size {
+			return nil, &ParseError{Line: line, Column: column, Err: ErrColumns}
+		}
+
+		for i, el := range row {
+			switch el {
+			case string(FREE), string(BLACK), string(WHITE), string(TABOO):
+				sb.WriteString(el)
+			default:
+				line, column = reader.FieldPos(i)
+				return nil, &ParseError{Line: line, Column: column, Err: fmt.Errorf("%w %q", ErrPiece, el)}
+			}
 		}
 	}
 
 	board.Board = sb.String()
-	board.currMove = WHITE
 
-	return &board
+	return &board, nil
 }
 
 func (b Board) Inverse() *Board {
-	inv := Board{size: b.size, currMove: UNDEFINED}
+	inv := Board{size: b.size, currMove: UNDEFINED, rules: b.rules}
 
 	sb := strings.Builder{}
 	for i := 0; i < inv.size*inv.size; i++ {
@@ -76,6 +132,19 @@ func (b Board) Inverse() *Board {
 	return &inv
 }
 
+// Set rules for the board and all boards derived from it
+func (b *Board) SetRules(r *Rules) {
+	b.rules = r
+}
+
+func (b Board) getRules() *Rules {
+	if b.rules == nil {
+		return DefaultRules
+	}
+
+	return b.rules
+}
+
 func (b Board) GetNeighbours() []Board {
 	var moves []Board
 	for j := 0; j < b.size; j++ {
@@ -87,6 +156,12 @@ func (b Board) GetNeighbours() []Board {
 				if v := b.getVerticalMoves(i, j); len(v) != 0 {
 					moves = append(moves, v...)
 				}
+				if b.getRules().Diagonal {
+					moves = append(moves, b.getDiagonalMoves(i, j)...)
+				}
+				if b.getRules().MultiJump {
+					moves = append(moves, b.getChainMoves(i, j)...)
+				}
 			}
 		}
 	}
@@ -160,6 +235,95 @@ func (b Board) getVerticalMoves(x, y int) []Board {
 	return moves
 }
 
+func (b Board) getDiagonalMoves(x, y int) []Board {
+	var moves []Board
+	for _, d := range diagonal {
+		if !b.isOnBoard(x+d[0], y+d[1]) {
+			continue
+		}
+		over := rune(b.Board[(y+d[1])*b.size+x+d[0]])
+		if over == FREE {
+			moves = append(moves, b.getMove(x, y, x+d[0], y+d[1]))
+			continue
+		}
+		if over != TABOO && b.isOnBoard(x+2*d[0], y+2*d[1]) &&
+			rune(b.Board[(y+2*d[1])*b.size+x+2*d[0]]) == FREE {
+			moves = append(moves, b.getMove(x, y, x+2*d[0], y+2*d[1]))
+		}
+	}
+
+	return moves
+}
+
+// Get moves to cells which can be reached only with a chain of several jumps
+func (b Board) getChainMoves(x, y int) []Board {
+	var moves []Board
+	for cell, jumps := range b.getChains(y*b.size + x) {
+		if jumps > 1 {
+			moves = append(moves, b.getMove(x, y, cell%b.size, cell/b.size))
+		}
+	}
+
+	return moves
+}
+
+// Get least number of jumps needed to reach every cell from origin.
+// Each cell is visited once, so chain can't go round in circles
+func (b Board) getChains(origin int) map[int]int {
+	visited := map[int]int{origin: 0}
+	queue := []int{origin}
+	for len(queue) != 0 {
+		curr := queue[0]
+		queue = queue[1:]
+		for _, next := range b.getJumps(curr, origin) {
+			if _, ok := visited[next]; ok {
+				continue
+			}
+			visited[next] = visited[curr] + 1
+			queue = append(queue, next)
+		}
+	}
+	delete(visited, origin)
+
+	return visited
+}
+
+// Directions of moves
+var (
+	orthogonal = [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	diagonal   = [][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+	allDirs    = append(append([][2]int{}, orthogonal...), diagonal...)
+)
+
+func (b Board) getDirections() [][2]int {
+	if b.getRules().Diagonal {
+		return allDirs
+	}
+
+	return orthogonal
+}
+
+// Get cells where checker lifted from origin can land after one jump from cell
+func (b Board) getJumps(cell, origin int) []int {
+	var res []int
+	x, y := cell%b.size, cell/b.size
+	for _, d := range b.getDirections() {
+		if !b.isOnBoard(x+2*d[0], y+2*d[1]) {
+			continue
+		}
+		over := (y+d[1])*b.size + x + d[0]
+		land := (y+2*d[1])*b.size + x + 2*d[0]
+		if over == origin || rune(b.Board[over]) == FREE || rune(b.Board[over]) == TABOO {
+			continue
+		}
+		if rune(b.Board[land]) == FREE {
+			res = append(res, land)
+		}
+	}
+
+	return res
+}
+
 func (b Board) isOnBoard(x, y int) bool {
 	if x < 0 || y < 0 || x > b.size-1 || y > b.size-1 {
 		return false
@@ -169,7 +333,7 @@ func (b Board) isOnBoard(x, y int) bool {
 }
 
 func (b Board) getMove(oldX, oldY, newX, newY int) Board {
-	m := Board{size: b.size}
+	m := Board{size: b.size, rules: b.rules}
 	if b.currMove == WHITE {
 		m.currMove = BLACK
 	} else {
@@ -182,16 +346,79 @@ func (b Board) getMove(oldX, oldY, newX, newY int) Board {
 	return m
 }
 
-// Sum of manh distances of each checker to corner
+// Cost of the move which turns b into to
+func (b Board) GetCost(to Board) int {
+	from, dest, ok := b.getMoved(to)
+	if !ok {
+		return 0
+	}
+
+	costs := b.getRules().Costs
+	dx, dy := dest%b.size-from%b.size, dest/b.size-from/b.size
+	if dx*dx <= 1 && dy*dy <= 1 {
+		return costs.Step
+	}
+	if jumps := b.getChains(from)[dest]; jumps > 1 {
+		return costs.Jump + (jumps-1)*costs.Chain
+	}
+
+	return costs.Jump
+}
+
+// Boards are equal if checkers are placed the same way, no matter whose move it is
+func (b Board) Equals(to Board) bool {
+	return b.Board == to.Board
+}
+
+// Find cells from which and to which checker was moved
+func (b Board) getMoved(to Board) (int, int, bool) {
+	from, dest := -1, -1
+	for i := 0; i < b.size*b.size; i++ {
+		if b.Board[i] == to.Board[i] {
+			continue
+		}
+		switch rune(to.Board[i]) {
+		case FREE:
+			from = i
+		default:
+			dest = i
+		}
+	}
+	if from == -1 || dest == -1 {
+		return 0, 0, false
+	}
+
+	return from, dest, true
+}
+
+// Cost of taking checkers of both players to their places on to.
+// Checker moved from cell c to cell t gets closer to corner by at most distance between c and t,
+// so checkers pass at least as many cells as they are farther from their corner than checkers of to
 func (b Board) Heuristic(to Board) int {
+	d := 0
+	for _, player := range []rune{BLACK, WHITE} {
+		if diff := b.Distance(player) - to.Distance(player); diff > 0 {
+			d += diff
+		}
+	}
+
+	return b.getRules().moveCost(d)
+}
+
+// Sum of distances of player's checkers to the corner they move to.
+// Manhattan distance for orthogonal moves, Chebyshev distance if diagonal moves are allowed
+func (b Board) Distance(player rune) int {
 	res := 0
 	for j := 0; j < b.size; j++ {
 		for i := 0; i < b.size; i++ {
-			switch rune(b.Board[j*b.size+i]) {
+			if rune(b.Board[j*b.size+i]) != player {
+				continue
+			}
+			switch player {
 			case BLACK:
-				res += b.size - 1 - i + b.size - 1 - j
+				res += b.distance(b.size-1-i, b.size-1-j)
 			case WHITE:
-				res += i + j
+				res += b.distance(i, j)
 			}
 		}
 	}
@@ -199,6 +426,40 @@ func (b Board) Heuristic(to Board) int {
 	return res
 }
 
+// Cost of moves which take checkers d cells in total.
+// Every cell is priced as a step unless rules are admissible.
+// Then a jump takes checker two cells and chain of k jumps takes it 2k cells
+// for at least k times the cheaper of jump and next jump
+func (r *Rules) moveCost(d int) int {
+	if !r.Admissible {
+		return d * r.Costs.Step
+	}
+	step, jump := r.Costs.Step, r.Costs.Jump
+	if r.MultiJump && r.Costs.Chain < jump {
+		jump = r.Costs.Chain
+	}
+	if jump >= 2*step {
+		return d * step
+	}
+	if step > jump {
+		step = jump
+	}
+
+	return d/2*jump + d%2*step
+}
+
+// Distance between cells with non-negative coordinate differences dx and dy
+func (b Board) distance(dx, dy int) int {
+	if !b.getRules().Diagonal {
+		return dx + dy
+	}
+	if dx > dy {
+		return dx
+	}
+
+	return dy
+}
+
 // Print pseudographic of board
 var Symbols = map[rune]string{BLACK: "◎", WHITE: "◉", TABOO: "✕", FREE: " "}
 
@@ -0,0 +1,28 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrSize    = errors.New("size must be a positive number")
+	ErrRows    = errors.New("number of rows doesn't match size")
+	ErrColumns = errors.New("number of cells in row doesn't match size")
+	ErrPiece   = errors.New("unknown piece")
+)
+
+// Error in board CSV with position where it was found
+type ParseError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -1 +1,41 @@
 package board
+
+// Player who makes next move
+func (b Board) CurrMove() rune {
+	return b.currMove
+}
+
+// Opponent of the player
+func Opponent(player rune) rune {
+	if player == WHITE {
+		return BLACK
+	}
+
+	return WHITE
+}
+
+// Check if player's checkers take all cells they take on board to
+func (b Board) Occupies(player rune, to Board) bool {
+	for i := 0; i < b.size*b.size; i++ {
+		if rune(to.Board[i]) == player && rune(b.Board[i]) != player {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Move checker of current player from (oldX, oldY) to (newX, newY) if rules allow it
+func (b Board) Move(oldX, oldY, newX, newY int) (Board, bool) {
+	if !b.isOnBoard(oldX, oldY) || !b.isOnBoard(newX, newY) {
+		return Board{}, false
+	}
+	for _, n := range b.GetNeighbours() {
+		from, dest, _ := b.getMoved(n)
+		if from == oldY*b.size+oldX && dest == newY*b.size+newX {
+			return n, true
+		}
+	}
+
+	return Board{}, false
+}
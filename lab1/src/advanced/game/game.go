@@ -0,0 +1,63 @@
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"src/board"
+	"time"
+)
+
+var Names = map[rune]string{board.WHITE: "White", board.BLACK: "Black"}
+
+// Play game of human against AI. Human's moves are read from in,
+// game ends when one of players takes the corner of opponent
+func Play(start board.Board, human rune, depth int, in io.Reader) {
+	terminal := *start.Inverse()
+	ai := NewAI(board.Opponent(human), depth, terminal)
+	scanner := bufio.NewScanner(in)
+
+	fmt.Printf("You play %s, columns and rows are numbered from 1, enter move as: x1 y1 x2 y2\n", Names[human])
+
+	curr := start
+	for turn := 1; ; {
+		curr.Print()
+		fmt.Println()
+
+		for _, player := range []rune{board.WHITE, board.BLACK} {
+			if curr.Occupies(player, terminal) {
+				fmt.Printf("%s wins in %d turns\n", Names[player], turn-1)
+				return
+			}
+		}
+		if len(curr.GetNeighbours()) == 0 {
+			fmt.Printf("%s has no moves left\n", Names[curr.CurrMove()])
+			return
+		}
+
+		if curr.CurrMove() == human {
+			fmt.Printf("%d. Your move: ", turn)
+			if !scanner.Scan() {
+				fmt.Println()
+				return
+			}
+			var x1, y1, x2, y2 int
+			if _, err := fmt.Sscan(scanner.Text(), &x1, &y1, &x2, &y2); err != nil {
+				fmt.Println("Can't parse move:", err)
+				continue
+			}
+			next, ok := curr.Move(x1-1, y1-1, x2-1, y2-1)
+			if !ok {
+				fmt.Println("Illegal move")
+				continue
+			}
+			curr = next
+		} else {
+			s := time.Now()
+			next, _ := ai.Move(curr)
+			fmt.Printf("%d. AI move, opened: %d, elapsed time: %s\n", turn, ai.Opened, time.Since(s))
+			curr = next
+		}
+		turn++
+	}
+}
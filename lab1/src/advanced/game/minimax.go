@@ -0,0 +1,97 @@
+package game
+
+import (
+	"math"
+	"src/board"
+)
+
+// Value of position where player has won
+const WIN = math.MaxInt32 / 2
+
+type AI struct {
+	Player   rune
+	Depth    int
+	Terminal board.Board // position where players swapped their corners
+	Opened   int         // number of positions evaluated during last move
+}
+
+func NewAI(player rune, depth int, terminal board.Board) *AI {
+	return &AI{Player: player, Depth: depth, Terminal: terminal}
+}
+
+// Choose best move with minimax and alpha-beta pruning
+func (ai *AI) Move(b board.Board) (board.Board, bool) {
+	ai.Opened = 0
+
+	var best board.Board
+	found := false
+	alpha := math.MinInt
+	for _, n := range b.GetNeighbours() {
+		v := ai.alphaBeta(n, ai.Depth-1, alpha, math.MaxInt)
+		if !found || v > alpha {
+			best = n
+			alpha = v
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+func (ai *AI) alphaBeta(b board.Board, depth, alpha, beta int) int {
+	ai.Opened++
+
+	if v, over := ai.evaluate(b); over || depth <= 0 {
+		// Prefer faster wins and slower losses
+		if v >= WIN {
+			return v + depth
+		}
+		if v <= -WIN {
+			return v - depth
+		}
+		return v
+	}
+
+	neighbours := b.GetNeighbours()
+	if len(neighbours) == 0 {
+		v, _ := ai.evaluate(b)
+		return v
+	}
+
+	// AI maximizes, opponent minimizes
+	if b.CurrMove() == ai.Player {
+		for _, n := range neighbours {
+			if v := ai.alphaBeta(n, depth-1, alpha, beta); v > alpha {
+				alpha = v
+			}
+			if alpha >= beta {
+				break
+			}
+		}
+		return alpha
+	}
+
+	for _, n := range neighbours {
+		if v := ai.alphaBeta(n, depth-1, alpha, beta); v < beta {
+			beta = v
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return beta
+}
+
+// Evaluation is difference of distances players have to go.
+// Second value is true if someone has already won
+func (ai *AI) evaluate(b board.Board) (int, bool) {
+	opponent := board.Opponent(ai.Player)
+	switch {
+	case b.Occupies(ai.Player, ai.Terminal):
+		return WIN, true
+	case b.Occupies(opponent, ai.Terminal):
+		return -WIN, true
+	}
+
+	return b.Distance(opponent) - b.Distance(ai.Player), false
+}
@@ -1,21 +1,53 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"src/a_search"
 	"src/board"
+	"src/game"
 	"time"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		println("usage: ./main <path_to_csv>")
+	step := flag.Int("step", board.DefaultCosts.Step, "cost of a step to adjacent cell")
+	jump := flag.Int("jump", board.DefaultCosts.Jump, "cost of a jump over a checker")
+	chain := flag.Int("chain", board.DefaultCosts.Chain, "cost of every next jump in a chain")
+	multiJump := flag.Bool("multijump", false, "allow chains of jumps in one move")
+	diagonal := flag.Bool("diagonal", false, "allow diagonal steps and jumps")
+	play := flag.String("play", "", "play against AI as white or black instead of searching path")
+	depth := flag.Int("depth", 4, "depth of AI search")
+	admissible := flag.Bool("admissible", false, "price distance with the cheapest moves, so path is optimal, but search is much slower")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		println("usage: ./main [flags] <path_to_csv>")
+		flag.PrintDefaults()
+		return
+	}
+	graphPathStart := flag.Arg(0)
+
+	start, err := board.NewBoardFromFile(graphPathStart)
+	if err != nil {
+		println("can't load board: " + err.Error())
+		return
+	}
+	start.SetRules(&board.Rules{MultiJump: *multiJump, Diagonal: *diagonal, Admissible: *admissible, Costs: board.Costs{Step: *step, Jump: *jump, Chain: *chain}})
+
+	switch *play {
+	case "":
+	case "white":
+		game.Play(*start, board.WHITE, *depth, os.Stdin)
+		return
+	case "black":
+		game.Play(*start, board.BLACK, *depth, os.Stdin)
+		return
+	default:
+		println("unknown side: " + *play)
 		return
 	}
-	graphPathStart := os.Args[1]
 
-	start := board.NewBoardFromFile(graphPathStart)
 	terminal := start.Inverse()
 
 	fmt.Println("Start:")
@@ -25,7 +57,7 @@ func main() {
 	fmt.Println()
 
 	s := time.Now()
-	path, dur, ok := a_search.A(*start, *terminal)
+	path, cost, ok := a_search.A(*start, *terminal)
 	f := time.Since(s)
 
 	if ok {
@@ -34,7 +66,8 @@ func main() {
 			path[i].Print()
 			fmt.Println()
 		}
-		fmt.Printf("Len: %d\n", dur)
+		fmt.Printf("Len: %d\n", len(path)-1)
+		fmt.Printf("Cost: %d\n", cost)
 		fmt.Printf("Elapsed time: %s\n", f)
 	} else {
 		fmt.Println("Couldn't find path")
@@ -31,7 +31,7 @@ func A(start, terminal board.Board) ([]board.Board, int, bool) {
 		closedCounter++
 
 		// If found end -> trace back and return path
-		if current == allNodes.get(terminal) {
+		if current.board.Equals(terminal) {
 			var p []board.Board
 			curr := current
 			for curr != nil {
@@ -44,7 +44,7 @@ func A(start, terminal board.Board) ([]board.Board, int, bool) {
 		}
 
 		for _, neighbour := range current.board.GetNeighbours() {
-			cost := current.cost + 1
+			cost := current.cost + current.board.GetCost(neighbour)
 			neighborNode := allNodes.get(neighbour)
 			// If already in OPENED -> check if cost is lower
 			if cost < neighborNode.cost {
@@ -0,0 +1,131 @@
+package a_search
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"src/board"
+)
+
+// One of two searches run by Bidirectional
+type direction struct {
+	allNodes   nodeMap
+	openedList *priorityQueue
+	neighbours func(b board.Board) []board.Board
+	cost       func(from, to board.Board) int
+	heuristic  func(b board.Board) int
+}
+
+func newDirection(from board.Board, neighbours func(board.Board) []board.Board,
+	cost func(board.Board, board.Board) int, heuristic func(board.Board) int) *direction {
+	d := &direction{
+		allNodes:   nodeMap{},
+		openedList: &priorityQueue{},
+		neighbours: neighbours,
+		cost:       cost,
+		heuristic:  heuristic,
+	}
+	heap.Init(d.openedList)
+
+	fromNode := d.allNodes.get(from)
+	fromNode.opened = true
+	fromNode.rank = heuristic(from)
+	heap.Push(d.openedList, fromNode)
+
+	return d
+}
+
+// Bidirectional A*. Forward search goes from start, backward search goes from terminal
+// through predecessors. Searches stop when the best path through met node can't be improved
+func Bidirectional(start, terminal board.Board) ([]board.Board, int, bool) {
+	forward := newDirection(start,
+		board.Board.GetNeighbours,
+		func(from, to board.Board) int { return from.GetCost(to) },
+		func(b board.Board) int { return b.Heuristic(terminal) })
+	backward := newDirection(terminal,
+		board.Board.GetPredecessors,
+		func(from, to board.Board) int { return to.GetCost(from) },
+		func(b board.Board) int { return b.ReverseHeuristic(start) })
+
+	best := math.MaxInt
+	var meet *node
+	if start.Equals(terminal) {
+		best, meet = 0, forward.allNodes.get(start)
+	}
+
+	closedCounter := 0
+
+	for forward.openedList.Len() != 0 && backward.openedList.Len() != 0 {
+		// If path can't be shortened -> stop
+		if best <= max((*forward.openedList)[0].rank, (*backward.openedList)[0].rank) {
+			break
+		}
+
+		// Expand direction with smaller OPENED list
+		d, other := forward, backward
+		if backward.openedList.Len() < forward.openedList.Len() {
+			d, other = backward, forward
+		}
+
+		// Close best node
+		current := heap.Pop(d.openedList).(*node)
+		current.opened = false
+		current.closed = true
+		closedCounter++
+
+		for _, neighbour := range d.neighbours(current.board) {
+			cost := current.cost + d.cost(current.board, neighbour)
+			neighborNode := d.allNodes.get(neighbour)
+			// If already in OPENED -> check if cost is lower
+			if cost < neighborNode.cost {
+				if neighborNode.opened {
+					heap.Remove(d.openedList, neighborNode.index)
+				}
+				neighborNode.opened = false
+				neighborNode.closed = false
+			}
+			// If completely new node -> add to OPENED
+			if !neighborNode.opened && !neighborNode.closed {
+				neighborNode.cost = cost
+				neighborNode.opened = true
+				neighborNode.rank = cost + d.heuristic(neighbour)
+				neighborNode.parent = current
+				heap.Push(d.openedList, neighborNode)
+
+				// If reached by other search -> remember path through it
+				if o, ok := other.allNodes[neighbour.Key()]; ok && cost+o.cost < best {
+					best = cost + o.cost
+					meet = neighborNode
+				}
+			}
+		}
+	}
+
+	// If searches never met -> failure
+	if meet == nil {
+		return nil, 0, false
+	}
+
+	// Stitch halves, path goes from terminal to start the same way A returns it
+	var p []board.Board
+	for curr := backward.allNodes[meet.board.Key()]; curr != nil; curr = curr.parent {
+		p = append(p, curr.board)
+	}
+	for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+		p[i], p[j] = p[j], p[i]
+	}
+	for curr := forward.allNodes[meet.board.Key()].parent; curr != nil; curr = curr.parent {
+		p = append(p, curr.board)
+	}
+
+	fmt.Printf("Iterations: %d\n", closedCounter)
+	return p, best, true
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,96 @@
+package a_search
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"src/board"
+)
+
+// Iterative deepening A*. Only boards of current path are kept in memory
+func IDA(start, terminal board.Board) ([]board.Board, int, bool) {
+	s := idaSearch{
+		terminal: terminal,
+		path:     []board.Board{start},
+		onPath:   map[board.Key]struct{}{start.Key(): {}},
+	}
+
+	bound := start.Heuristic(terminal)
+	for {
+		t, found := s.search(0, bound)
+		if found {
+			// Path is returned from terminal to start, the same way A does
+			p := make([]board.Board, 0, len(s.path))
+			for i := len(s.path) - 1; i >= 0; i-- {
+				p = append(p, s.path[i])
+			}
+
+			fmt.Printf("Iterations: %d\n", s.closedCounter)
+			return p, s.cost, true
+		}
+		// If bound can't be raised -> failure
+		if t == math.MaxInt {
+			return nil, 0, false
+		}
+		bound = t
+	}
+}
+
+type idaSearch struct {
+	terminal      board.Board
+	path          []board.Board
+	onPath        map[board.Key]struct{}
+	cost          int
+	closedCounter int
+}
+
+// Depth-first search limited by bound on cost + heuristic.
+// Returns smallest rank exceeding bound and true if terminal is found
+func (s *idaSearch) search(cost, bound int) (int, bool) {
+	current := s.path[len(s.path)-1]
+	rank := cost + current.Heuristic(s.terminal)
+	if rank > bound {
+		return rank, false
+	}
+
+	s.closedCounter++
+
+	if current.Equals(s.terminal) {
+		s.cost = cost
+		return rank, true
+	}
+
+	// Try the most promising neighbours first
+	neighbours := current.GetNeighbours()
+	h := make(map[board.Key]int, len(neighbours))
+	for _, neighbour := range neighbours {
+		h[neighbour.Key()] = neighbour.Heuristic(s.terminal)
+	}
+	sort.Slice(neighbours, func(i, j int) bool {
+		return h[neighbours[i].Key()] < h[neighbours[j].Key()]
+	})
+
+	min := math.MaxInt
+	for _, neighbour := range neighbours {
+		// Skip cycles on current path
+		if _, ok := s.onPath[neighbour.Key()]; ok {
+			continue
+		}
+
+		s.path = append(s.path, neighbour)
+		s.onPath[neighbour.Key()] = struct{}{}
+
+		t, found := s.search(cost+current.GetCost(neighbour), bound)
+		if found {
+			return t, true
+		}
+		if t < min {
+			min = t
+		}
+
+		delete(s.onPath, neighbour.Key())
+		s.path = s.path[:len(s.path)-1]
+	}
+
+	return min, false
+}
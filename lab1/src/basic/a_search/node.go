@@ -12,15 +12,15 @@ type node struct {
 	rank   int
 }
 
-type nodeMap map[board.Board]*node
+type nodeMap map[board.Key]*node
 
 func (nm nodeMap) get(p board.Board) *node {
-	n, ok := nm[p]
+	n, ok := nm[p.Key()]
 	if !ok {
 		n = &node{
 			board: p,
 		}
-		nm[p] = n
+		nm[p.Key()] = n
 	}
 	return n
 }
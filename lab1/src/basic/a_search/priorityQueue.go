@@ -6,7 +6,12 @@ func (pq priorityQueue) Len() int {
 	return len(pq)
 }
 
+// Of nodes with equal rank the one with greater cost is closer to terminal
 func (pq priorityQueue) Less(i, j int) bool {
+	if pq[i].rank == pq[j].rank {
+		return pq[i].cost > pq[j].cost
+	}
+
 	return pq[i].rank < pq[j].rank
 }
 
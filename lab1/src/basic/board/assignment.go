@@ -0,0 +1,134 @@
+package board
+
+import "math"
+
+// Cells terminal board is matched with
+type target struct {
+	checkers bitset
+	taboo    bitset
+}
+
+// Get distances in relaxed game from every checker of to (rows) to every cell (columns).
+// Distances are computed once for each terminal board and kept in its rules
+func getTargetDistances(to Board) [][]int {
+	rules := to.getRules()
+	key := target{checkers: to.checkers, taboo: to.taboo}
+	rules.mu.Lock()
+	defer rules.mu.Unlock()
+	if d, ok := rules.targets[key]; ok {
+		return d
+	}
+
+	db := &Database{
+		Size:       to.size,
+		MultiJump:  rules.MultiJump,
+		Diagonal:   rules.Diagonal,
+		Admissible: rules.Admissible,
+		Costs:      rules.Costs,
+	}
+	graph := db.getGraph(to)
+	var d [][]int
+	for _, t := range to.checkers.cells() {
+		db.Targets = []int{t}
+		d = append(d, db.getDist(graph))
+	}
+	if rules.targets == nil {
+		rules.targets = map[target][][]int{}
+	}
+	rules.targets[key] = d
+
+	return d
+}
+
+// Least total cost of moving checkers of b to distinct checker cells of to.
+// TABOO cells are respected, other checkers are considered to be free cells or stones to jump over,
+// so estimate suits any terminal board and is admissible with admissible rules.
+// It is slower than distance to corner
+func (b Board) getAssignmentCost(to Board) int {
+	dist := getTargetDistances(to)
+	cells := b.checkers.cells()
+	if len(cells) > len(dist) {
+		return unreachable
+	}
+
+	cost := make([][]int, len(cells))
+	for i, c := range cells {
+		cost[i] = make([]int, len(dist))
+		for j := range dist {
+			cost[i][j] = dist[j][c]
+		}
+	}
+
+	return hungarian(cost)
+}
+
+// Hungarian algorithm with potentials for n x m cost matrix, n <= m.
+// Returns least cost of assigning every row to distinct column
+func hungarian(cost [][]int) int {
+	n := len(cost)
+	if n == 0 {
+		return 0
+	}
+	m := len(cost[0])
+
+	// Rows and columns are numbered from 1, column 0 is fictive
+	u := make([]int, n+1)
+	v := make([]int, m+1)
+	p := make([]int, m+1) // row assigned to column
+	way := make([]int, m+1)
+	minv := make([]int, m+1)
+	used := make([]bool, m+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		for j := range minv {
+			minv[j] = math.MaxInt
+			used[j] = false
+		}
+		for {
+			used[j0] = true
+			i0, delta, j1 := p[j0], math.MaxInt, 0
+			for j := 1; j <= m; j++ {
+				if used[j] {
+					continue
+				}
+				if c := cost[i0-1][j-1] - u[i0] - v[j]; c < minv[j] {
+					minv[j] = c
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= m; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+		// Flip assignment along augmenting path
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	res := 0
+	for j := 1; j <= m; j++ {
+		if p[j] != 0 {
+			res += cost[p[j]-1][j-1]
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,43 @@
+package board
+
+import "math/bits"
+
+// Max size of board side which fits into bitset
+const MaxSize = 16
+
+// Set of cells, bit i stands for cell i
+type bitset [MaxSize * MaxSize / 64]uint64
+
+func (s bitset) has(i int) bool {
+	return s[i/64]&(1<<(i%64)) != 0
+}
+
+func (s *bitset) set(i int) {
+	s[i/64] |= 1 << (i % 64)
+}
+
+func (s *bitset) unset(i int) {
+	s[i/64] &^= 1 << (i % 64)
+}
+
+// Indexes of cells in set in ascending order
+func (s bitset) cells() []int {
+	var res []int
+	for w, word := range s {
+		for word != 0 {
+			res = append(res, w*64+bits.TrailingZeros64(word))
+			word &= word - 1
+		}
+	}
+
+	return res
+}
+
+// Cells which are in s, but not in o
+func (s bitset) minus(o bitset) bitset {
+	for w := range s {
+		s[w] &^= o[w]
+	}
+
+	return s
+}
@@ -3,9 +3,10 @@ package board
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
-	"strings"
+	"sync"
 )
 
 const (
@@ -14,55 +15,144 @@ const (
 	TABOO rune = '2'
 )
 
+// Prices of checker moves
+type Costs struct {
+	Step  int // move to adjacent free cell
+	Jump  int // jump over a checker
+	Chain int // every next jump of multi-jump chain
+}
+
+var DefaultCosts = Costs{Step: 1, Jump: 1, Chain: 1}
+
+// Rules the board is played by
+type Rules struct {
+	MultiJump  bool // allow chains of jumps in one move
+	Diagonal   bool // allow diagonal steps and jumps
+	Assignment bool // always estimate with optimal assignment of checkers to terminal board
+	Admissible bool // price distance with the cheapest moves, so A* finds optimal path, but opens far more boards
+	Costs      Costs
+	Database   *Database // precomputed heuristic, distance to corner is used if nil
+
+	mu      sync.Mutex
+	targets map[target][][]int // distances to checkers of terminal boards for assignment
+}
+
+var DefaultRules = &Rules{Costs: DefaultCosts}
+
 type Board struct {
-	size  int
-	Board string
+	size     int
+	checkers bitset
+	taboo    bitset
+	rules    *Rules
 }
 
-func NewBoardFromFile(path string) *Board {
+// Compact key of board, which differs for boards with checkers placed differently
+type Key bitset
+
+func (b Board) Key() Key {
+	return Key(b.checkers)
+}
+
+func NewBoardFromFile(path string) (*Board, error) {
 	csvConf, err := os.Open(path)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	defer func() {
-		if err := csvConf.Close(); err != nil {
-			panic(err)
-		}
-	}()
+	defer csvConf.Close()
+
+	return ReadBoard(csvConf)
+}
 
-	reader := csv.NewReader(csvConf)
+// Read board from CSV: size in the first line, then rows of pieces separated by spaces
+func ReadBoard(r io.Reader) (*Board, error) {
+	reader := csv.NewReader(r)
 	reader.Comma = ' '
 	reader.FieldsPerRecord = -1
-	data, err := reader.ReadAll()
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, &ParseError{Line: 1, Column: 1, Err: ErrSize}
+	}
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	line, column := reader.FieldPos(0)
+	size, err := strconv.Atoi(header[0])
+	if err != nil || len(header) != 1 || size < 1 || size > MaxSize {
+		return nil, &ParseError{Line: line, Column: column, Err: ErrSize}
 	}
 
-	var board Board
-	board.size, _ = strconv.Atoi(data[0][0])
-	data = data[1:]
-	sb := strings.Builder{}
-	for _, row := range data {
-		for _, el := range row {
-			sb.WriteString(el)
+	board := Board{size: size, rules: DefaultRules}
+	for j := 0; ; j++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			if j != size {
+				return nil, &ParseError{Line: line + 1, Column: 1, Err: ErrRows}
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, column = reader.FieldPos(0)
+		// Skip trailing spaces
+		for len(row) > 1 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		if j == size {
+			return nil, &ParseError{Line: line, Column: column, Err: ErrRows}
+		}
+		if len(row) != size {
+			return nil, &ParseError{Line: line, Column: column, Err: ErrColumns}
+		}
+
+		for i, el := range row {
+			switch el {
+			case string(FREE):
+			case string(BLACK):
+				board.checkers.set(j*board.size + i)
+			case string(TABOO):
+				board.taboo.set(j*board.size + i)
+			default:
+				line, column = reader.FieldPos(i)
+				return nil, &ParseError{Line: line, Column: column, Err: fmt.Errorf("%w %q", ErrPiece, el)}
+			}
 		}
 	}
 
-	board.Board = sb.String()
+	return &board, nil
+}
+
+// Set rules for the board and all boards derived from it
+func (b *Board) SetRules(r *Rules) {
+	b.rules = r
+}
+
+func (b Board) getRules() *Rules {
+	if b.rules == nil {
+		return DefaultRules
+	}
 
-	return &board
+	return b.rules
 }
 
+// Get boards reachable with one move of any checker.
+// Moves start from BLACK checkers only, FREE and TABOO cells are never moved
 func (b Board) GetNeighbours() []Board {
 	var moves []Board
-	for j := 0; j < b.size; j++ {
-		for i := 0; i < b.size; i++ {
-			if h := b.getHorizontalMoves(i, j); len(h) != 0 {
-				moves = append(moves, h...)
-			}
-			if v := b.getVerticalMoves(i, j); len(v) != 0 {
-				moves = append(moves, v...)
-			}
+	for _, cell := range b.checkers.cells() {
+		i, j := cell%b.size, cell/b.size
+		if h := b.getHorizontalMoves(i, j); len(h) != 0 {
+			moves = append(moves, h...)
+		}
+		if v := b.getVerticalMoves(i, j); len(v) != 0 {
+			moves = append(moves, v...)
+		}
+		if b.getRules().Diagonal {
+			moves = append(moves, b.getDiagonalMoves(i, j)...)
+		}
+		if b.getRules().MultiJump {
+			moves = append(moves, b.getChainMoves(i, j)...)
 		}
 	}
 
@@ -73,31 +163,38 @@ func (b Board) GetNeighbours() []Board {
 	return moves
 }
 
+// Get boards from which b can be reached with one move.
+// Every move can be made backwards: step and jump are symmetric,
+// and chain of jumps passes the same cells in reverse order
+func (b Board) GetPredecessors() []Board {
+	return b.GetNeighbours()
+}
+
 func (b Board) getHorizontalMoves(x, y int) []Board {
 	var moves []Board
 	// right
 	if b.isOnBoard(x+1, y) {
-		if rune(b.Board[y*b.size+x+1]) == FREE {
+		if b.at(y*b.size+x+1) == FREE {
 			moves = append(moves, b.getMove(x, y, x+1, y))
 		}
 	}
 	if b.isOnBoard(x+2, y) &&
-		rune(b.Board[y*b.size+x+1]) != FREE &&
-		rune(b.Board[y*b.size+x+1]) != TABOO {
-		if rune(b.Board[y*b.size+x+2]) == FREE {
+		b.at(y*b.size+x+1) != FREE &&
+		b.at(y*b.size+x+1) != TABOO {
+		if b.at(y*b.size+x+2) == FREE {
 			moves = append(moves, b.getMove(x, y, x+2, y))
 		}
 	}
 	// left
 	if b.isOnBoard(x-1, y) {
-		if rune(b.Board[y*b.size+x-1]) == FREE {
+		if b.at(y*b.size+x-1) == FREE {
 			moves = append(moves, b.getMove(x, y, x-1, y))
 		}
 	}
 	if b.isOnBoard(x-2, y) {
-		if rune(b.Board[y*b.size+x-2]) == FREE &&
-			rune(b.Board[y*b.size+x-1]) != FREE &&
-			rune(b.Board[y*b.size+x-1]) != TABOO {
+		if b.at(y*b.size+x-2) == FREE &&
+			b.at(y*b.size+x-1) != FREE &&
+			b.at(y*b.size+x-1) != TABOO {
 			moves = append(moves, b.getMove(x, y, x-2, y))
 		}
 	}
@@ -109,27 +206,27 @@ func (b Board) getVerticalMoves(x, y int) []Board {
 	var moves []Board
 	// top
 	if b.isOnBoard(x, y+1) {
-		if rune(b.Board[(y+1)*b.size+x]) == FREE {
+		if b.at((y+1)*b.size+x) == FREE {
 			moves = append(moves, b.getMove(x, y, x, y+1))
 		}
 	}
 	if b.isOnBoard(x, y+2) &&
-		rune(b.Board[(y+1)*b.size+x]) != FREE &&
-		rune(b.Board[(y+1)*b.size+x]) != TABOO {
-		if rune(b.Board[(y+2)*b.size+x]) == FREE {
+		b.at((y+1)*b.size+x) != FREE &&
+		b.at((y+1)*b.size+x) != TABOO {
+		if b.at((y+2)*b.size+x) == FREE {
 			moves = append(moves, b.getMove(x, y, x, y+2))
 		}
 	}
 	// bottom
 	if b.isOnBoard(x, y-1) {
-		if rune(b.Board[(y-1)*b.size+x]) == FREE {
+		if b.at((y-1)*b.size+x) == FREE {
 			moves = append(moves, b.getMove(x, y, x, y-1))
 		}
 	}
 	if b.isOnBoard(x, y-2) {
-		if rune(b.Board[(y-2)*b.size+x]) == FREE &&
-			rune(b.Board[(y-1)*b.size+x]) != FREE &&
-			rune(b.Board[(y-1)*b.size+x]) != TABOO {
+		if b.at((y-2)*b.size+x) == FREE &&
+			b.at((y-1)*b.size+x) != FREE &&
+			b.at((y-1)*b.size+x) != TABOO {
 			moves = append(moves, b.getMove(x, y, x, y-2))
 		}
 	}
@@ -137,6 +234,95 @@ func (b Board) getVerticalMoves(x, y int) []Board {
 	return moves
 }
 
+func (b Board) getDiagonalMoves(x, y int) []Board {
+	var moves []Board
+	for _, d := range diagonal {
+		if !b.isOnBoard(x+d[0], y+d[1]) {
+			continue
+		}
+		over := b.at((y+d[1])*b.size + x + d[0])
+		if over == FREE {
+			moves = append(moves, b.getMove(x, y, x+d[0], y+d[1]))
+			continue
+		}
+		if over != TABOO && b.isOnBoard(x+2*d[0], y+2*d[1]) &&
+			b.at((y+2*d[1])*b.size+x+2*d[0]) == FREE {
+			moves = append(moves, b.getMove(x, y, x+2*d[0], y+2*d[1]))
+		}
+	}
+
+	return moves
+}
+
+// Get moves to cells which can be reached only with a chain of several jumps
+func (b Board) getChainMoves(x, y int) []Board {
+	var moves []Board
+	for cell, jumps := range b.getChains(y*b.size + x) {
+		if jumps > 1 {
+			moves = append(moves, b.getMove(x, y, cell%b.size, cell/b.size))
+		}
+	}
+
+	return moves
+}
+
+// Get least number of jumps needed to reach every cell from origin.
+// Each cell is visited once, so chain can't go round in circles
+func (b Board) getChains(origin int) map[int]int {
+	visited := map[int]int{origin: 0}
+	queue := []int{origin}
+	for len(queue) != 0 {
+		curr := queue[0]
+		queue = queue[1:]
+		for _, next := range b.getJumps(curr, origin) {
+			if _, ok := visited[next]; ok {
+				continue
+			}
+			visited[next] = visited[curr] + 1
+			queue = append(queue, next)
+		}
+	}
+	delete(visited, origin)
+
+	return visited
+}
+
+// Directions of moves
+var (
+	orthogonal = [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	diagonal   = [][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+	allDirs    = append(append([][2]int{}, orthogonal...), diagonal...)
+)
+
+func (b Board) getDirections() [][2]int {
+	if b.getRules().Diagonal {
+		return allDirs
+	}
+
+	return orthogonal
+}
+
+// Get cells where checker lifted from origin can land after one jump from cell
+func (b Board) getJumps(cell, origin int) []int {
+	var res []int
+	x, y := cell%b.size, cell/b.size
+	for _, d := range b.getDirections() {
+		if !b.isOnBoard(x+2*d[0], y+2*d[1]) {
+			continue
+		}
+		over := (y+d[1])*b.size + x + d[0]
+		land := (y+2*d[1])*b.size + x + 2*d[0]
+		if over == origin || b.at(over) == FREE || b.at(over) == TABOO {
+			continue
+		}
+		if b.at(land) == FREE {
+			res = append(res, land)
+		}
+	}
+
+	return res
+}
+
 func (b Board) isOnBoard(x, y int) bool {
 	if x < 0 || y < 0 || x > b.size-1 || y > b.size-1 {
 		return false
@@ -146,51 +332,195 @@ func (b Board) isOnBoard(x, y int) bool {
 }
 
 func (b Board) getMove(oldX, oldY, newX, newY int) Board {
-	m := Board{size: b.size}
-	r := []rune(b.Board)
-	r[oldY*m.size+oldX], r[newY*m.size+newX] = FREE, r[oldY*m.size+oldX]
-	m.Board = string(r)
+	m := b
+	m.checkers.unset(oldY*b.size + oldX)
+	m.checkers.set(newY*b.size + newX)
 
 	return m
 }
 
-// Sum of manh distances of each checker to corner
+// Get what is placed on cell
+func (b Board) at(i int) rune {
+	switch {
+	case b.checkers.has(i):
+		return BLACK
+	case b.taboo.has(i):
+		return TABOO
+	}
+
+	return FREE
+}
+
+// Cost of the move which turns b into to
+func (b Board) GetCost(to Board) int {
+	from, dest, ok := b.getMoved(to)
+	if !ok {
+		return 0
+	}
+
+	costs := b.getRules().Costs
+	dx, dy := dest%b.size-from%b.size, dest/b.size-from/b.size
+	if dx*dx <= 1 && dy*dy <= 1 {
+		return costs.Step
+	}
+	if jumps := b.getChains(from)[dest]; jumps > 1 {
+		return costs.Jump + (jumps-1)*costs.Chain
+	}
+
+	return costs.Jump
+}
+
+// Boards are equal if checkers are placed the same way
+func (b Board) Equals(to Board) bool {
+	return b.checkers == to.checkers
+}
+
+// Find cells from which and to which checker was moved
+func (b Board) getMoved(to Board) (int, int, bool) {
+	from, dest := b.checkers.minus(to.checkers).cells(), to.checkers.minus(b.checkers).cells()
+	if len(from) != 1 || len(dest) != 1 {
+		return 0, 0, false
+	}
+
+	return from[0], dest[0], true
+}
+
+// Cost of taking checkers to checkers of to.
+// If rules have database, estimate is taken from it.
+// If rules require assignment or checkers of to don't fill the opposite corner,
+// checkers are matched with checkers of to
 func (b Board) Heuristic(to Board) int {
-	res := 0
-	for j := 0; j < b.size; j++ {
-		for i := 0; i < b.size; i++ {
-			switch rune(b.Board[j*b.size+i]) {
-			case BLACK:
-				res += (b.size - 1 - i) + (b.size - 1 - j)
-			}
+	if db := b.getRules().Database; db != nil {
+		return db.estimate(b)
+	}
+	if b.getRules().Assignment || !to.fillsCorner(b.size-1, b.size-1) {
+		return b.getAssignmentCost(to)
+	}
+
+	return b.getCornerCost(to, b.size-1, b.size-1)
+}
+
+// Cost of taking checkers back to checkers of to.
+// Used by backward search, which moves from terminal board to start,
+// so database generated for terminal board isn't used.
+// Checkers are matched with checkers of to unless they fill the top left corner
+func (b Board) ReverseHeuristic(to Board) int {
+	if b.getRules().Assignment || !to.fillsCorner(0, 0) {
+		return b.getAssignmentCost(to)
+	}
+
+	return b.getCornerCost(to, 0, 0)
+}
+
+// Checker moved from cell c to cell t gets closer to corner by at most distance between c and t,
+// so checkers of b pass at least as many cells as they are farther from corner (x, y) than checkers of to.
+// Manhattan distance for orthogonal moves, Chebyshev distance if diagonal moves are allowed
+func (b Board) getCornerCost(to Board, x, y int) int {
+	d := b.getCornerDistance(x, y) - to.getCornerDistance(x, y)
+	if d < 0 {
+		d = 0
+	}
+
+	return b.getRules().moveCost(d)
+}
+
+// Check if no free cell is closer to corner (x, y) than any checker
+func (b Board) fillsCorner(x, y int) bool {
+	farthest := 0
+	for _, cell := range b.checkers.cells() {
+		if d := b.distance(abs(cell%b.size-x), abs(cell/b.size-y)); d > farthest {
+			farthest = d
+		}
+	}
+	for cell := 0; cell < b.size*b.size; cell++ {
+		if b.at(cell) == FREE && b.distance(abs(cell%b.size-x), abs(cell/b.size-y)) < farthest {
+			return false
 		}
 	}
 
+	return true
+}
+
+// Sum of distances of checkers to corner (x, y)
+func (b Board) getCornerDistance(x, y int) int {
+	res := 0
+	for _, cell := range b.checkers.cells() {
+		res += b.distance(abs(cell%b.size-x), abs(cell/b.size-y))
+	}
+
 	return res
 }
 
+// Cost of moves which take checkers d cells in total.
+// Every cell is priced as a step unless rules are admissible.
+// Then a jump takes checker two cells and chain of k jumps takes it 2k cells
+// for at least k times the cheaper of jump and next jump
+func (r *Rules) moveCost(d int) int {
+	step, jump := r.Costs.Step, r.jumpCost()
+	if jump >= 2*step {
+		return d * step
+	}
+	if step > jump {
+		step = jump
+	}
+
+	return d/2*jump + d%2*step
+}
+
+// Cost of a jump over two cells in relaxed game
+func (r *Rules) jumpCost() int {
+	if !r.Admissible {
+		return 2 * r.Costs.Step
+	}
+	if r.MultiJump && r.Costs.Chain < r.Costs.Jump {
+		return r.Costs.Chain
+	}
+
+	return r.Costs.Jump
+}
+
+// Distance between cells with non-negative coordinate differences dx and dy
+func (b Board) distance(dx, dy int) int {
+	if !b.getRules().Diagonal {
+		return dx + dy
+	}
+	if dx > dy {
+		return dx
+	}
+
+	return dy
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+
+	return a
+}
+
 // Print pseudographic of board
 var Symbols = map[rune]string{BLACK: "◎", TABOO: "✕", FREE: " "}
 
 func (b Board) Print() {
 	// First row
-	fmt.Print("⎾" + Symbols[rune(b.Board[0])])
+	fmt.Print("⎾" + Symbols[b.at(0)])
 	for i := 1; i < b.size; i++ {
-		fmt.Print("⏉" + Symbols[rune(b.Board[i])])
+		fmt.Print("⏉" + Symbols[b.at(i)])
 	}
 	fmt.Print("⏋\n")
 	// Middle
 	for j := 1; j < b.size-1; j++ {
-		fmt.Print("⎾" + Symbols[rune(b.Board[j*b.size])])
+		fmt.Print("⎾" + Symbols[b.at(j*b.size)])
 		for i := 1; i < b.size; i++ {
-			fmt.Print("⏉" + Symbols[rune(b.Board[j*b.size+i])])
+			fmt.Print("⏉" + Symbols[b.at(j*b.size+i)])
 		}
 		fmt.Print("⏋\n")
 	}
 	// Last row
-	fmt.Print("⎾" + Symbols[rune(b.Board[b.size*(b.size-1)])])
+	fmt.Print("⎾" + Symbols[b.at(b.size*(b.size-1))])
 	for i := 1; i < b.size; i++ {
-		fmt.Print("⏉" + Symbols[rune(b.Board[b.size*(b.size-1)+i])])
+		fmt.Print("⏉" + Symbols[b.at(b.size*(b.size-1)+i)])
 	}
 	fmt.Print("⏋\n")
 }
@@ -0,0 +1,263 @@
+package board
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"os"
+)
+
+// Cost of reaching cell which can't be reached at all
+const unreachable = 1 << 20
+
+// Precomputed heuristic for one terminal board.
+// Costs are found for relaxed game, where any cell except TABOO can be jumped over.
+// With admissible rules they never exceed true cost of moving checkers
+type Database struct {
+	Size       int
+	MultiJump  bool
+	Diagonal   bool
+	Admissible bool
+	Costs      Costs
+	Taboo      []int // cells database is generated around
+	Targets    []int // cells of checkers on terminal board
+	Dist       []int // least cost for checker on cell to reach any target
+	Pairs      []int // least cost for checkers on cells i < j to reach two targets, nil if not generated
+}
+
+// Generate database for terminal board with rules of this board.
+// Pattern database for pairs of checkers is generated if pairs is true
+func NewDatabase(to Board, pairs bool) *Database {
+	rules := to.getRules()
+	db := &Database{
+		Size:       to.size,
+		MultiJump:  rules.MultiJump,
+		Diagonal:   rules.Diagonal,
+		Admissible: rules.Admissible,
+		Costs:      rules.Costs,
+		Taboo:      to.taboo.cells(),
+		Targets:    to.checkers.cells(),
+	}
+
+	graph := db.getGraph(to)
+	db.Dist = db.getDist(graph)
+	if pairs {
+		db.Pairs = db.getPairs(graph)
+	}
+
+	return db
+}
+
+func LoadDatabase(path string) (*Database, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	db := &Database{}
+	if err = gob.NewDecoder(f).Decode(db); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (db *Database) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = gob.NewEncoder(f).Encode(db); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Check if database was generated for terminal board to and its rules
+func (db *Database) Matches(to Board) bool {
+	rules := to.getRules()
+	if db.Size != to.size || db.MultiJump != rules.MultiJump || db.Diagonal != rules.Diagonal ||
+		db.Admissible != rules.Admissible || db.Costs != rules.Costs {
+		return false
+	}
+
+	return equalCells(db.Taboo, to.taboo.cells()) && equalCells(db.Targets, to.checkers.cells())
+}
+
+func equalCells(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Estimate cost of moving checkers of b to targets.
+// Checkers are split into pairs in order of cells, costs of pairs are added up
+func (db *Database) estimate(b Board) int {
+	res := 0
+	cells := b.checkers.cells()
+	k := 0
+	if db.Pairs != nil {
+		n := db.Size * db.Size
+		for ; k+1 < len(cells); k += 2 {
+			res += db.Pairs[cells[k]*n+cells[k+1]]
+		}
+	}
+	for ; k < len(cells); k++ {
+		res += db.Dist[cells[k]]
+	}
+
+	return res
+}
+
+// Move of relaxed game
+type edge struct {
+	to   int
+	cost int
+}
+
+// Get moves of single checker in relaxed game for every cell
+func (db *Database) getGraph(b Board) [][]edge {
+	jump := (&Rules{MultiJump: db.MultiJump, Admissible: db.Admissible, Costs: db.Costs}).jumpCost()
+
+	graph := make([][]edge, db.Size*db.Size)
+	for cell := range graph {
+		if b.taboo.has(cell) {
+			continue
+		}
+		x, y := cell%b.size, cell/b.size
+		for _, d := range b.getDirections() {
+			if !b.isOnBoard(x+d[0], y+d[1]) {
+				continue
+			}
+			next := (y+d[1])*b.size + x + d[0]
+			if b.taboo.has(next) {
+				continue
+			}
+			graph[cell] = append(graph[cell], edge{to: next, cost: db.Costs.Step})
+			if !b.isOnBoard(x+2*d[0], y+2*d[1]) {
+				continue
+			}
+			land := (y+2*d[1])*b.size + x + 2*d[0]
+			if !b.taboo.has(land) {
+				graph[cell] = append(graph[cell], edge{to: land, cost: jump})
+			}
+		}
+	}
+
+	return graph
+}
+
+// Dijkstra from all targets at once. Moves of relaxed game are symmetric,
+// so distance from target to cell is the same as from cell to target
+func (db *Database) getDist(graph [][]edge) []int {
+	dist := make([]int, len(graph))
+	for i := range dist {
+		dist[i] = unreachable
+	}
+	queue := &stateQueue{}
+	for _, t := range db.Targets {
+		dist[t] = 0
+		heap.Push(queue, state{key: t})
+	}
+
+	for queue.Len() != 0 {
+		curr := heap.Pop(queue).(state)
+		if curr.cost > dist[curr.key] {
+			continue
+		}
+		for _, e := range graph[curr.key] {
+			if c := curr.cost + e.cost; c < dist[e.to] {
+				dist[e.to] = c
+				heap.Push(queue, state{key: e.to, cost: c})
+			}
+		}
+	}
+
+	return dist
+}
+
+// Dijkstra over positions of two checkers from all pairs of targets.
+// Position of checkers on cells i < j has key i*n+j
+func (db *Database) getPairs(graph [][]edge) []int {
+	n := len(graph)
+	dist := make([]int, n*n)
+	for i := range dist {
+		dist[i] = unreachable
+	}
+	queue := &stateQueue{}
+	for a := 0; a < len(db.Targets); a++ {
+		for b := a + 1; b < len(db.Targets); b++ {
+			key := db.Targets[a]*n + db.Targets[b]
+			dist[key] = 0
+			heap.Push(queue, state{key: key})
+		}
+	}
+
+	for queue.Len() != 0 {
+		curr := heap.Pop(queue).(state)
+		if curr.cost > dist[curr.key] {
+			continue
+		}
+		i, j := curr.key/n, curr.key%n
+		// Move one of checkers, the other one stays
+		for _, pair := range [][2]int{{i, j}, {j, i}} {
+			moving, staying := pair[0], pair[1]
+			for _, e := range graph[moving] {
+				if e.to == staying {
+					continue
+				}
+				key := e.to*n + staying
+				if e.to > staying {
+					key = staying*n + e.to
+				}
+				if c := curr.cost + e.cost; c < dist[key] {
+					dist[key] = c
+					heap.Push(queue, state{key: key, cost: c})
+				}
+			}
+		}
+	}
+
+	return dist
+}
+
+type state struct {
+	key  int
+	cost int
+}
+
+type stateQueue []state
+
+func (q stateQueue) Len() int {
+	return len(q)
+}
+
+func (q stateQueue) Less(i, j int) bool {
+	return q[i].cost < q[j].cost
+}
+
+func (q stateQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *stateQueue) Push(x interface{}) {
+	*q = append(*q, x.(state))
+}
+
+func (q *stateQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	s := old[n-1]
+	*q = old[0 : n-1]
+
+	return s
+}
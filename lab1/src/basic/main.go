@@ -1,23 +1,72 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"os"
 	"src/a_search"
 	"src/board"
 	"time"
 )
 
 func main() {
-	if len(os.Args) < 3 {
-		println("usage: ./main <path_to_csv>")
+	step := flag.Int("step", board.DefaultCosts.Step, "cost of a step to adjacent cell")
+	jump := flag.Int("jump", board.DefaultCosts.Jump, "cost of a jump over a checker")
+	chain := flag.Int("chain", board.DefaultCosts.Chain, "cost of every next jump in a chain")
+	multiJump := flag.Bool("multijump", false, "allow chains of jumps in one move")
+	diagonal := flag.Bool("diagonal", false, "allow diagonal steps and jumps")
+	algo := flag.String("algo", "a", "search algorithm: a, ida or bi")
+	dbPath := flag.String("db", "", "load heuristic database from file")
+	genDbPath := flag.String("gendb", "", "generate heuristic database for terminal board and save it to file")
+	pairs := flag.Bool("pairs", false, "add pattern database for pairs of checkers to generated database")
+	assignment := flag.Bool("assign", false, "estimate with optimal assignment of checkers to terminal board even if it fills the corner")
+	admissible := flag.Bool("admissible", false, "price distance with the cheapest moves, so path is optimal, but search is much slower")
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		println("usage: ./main [flags] <path_to_start_csv> <path_to_terminal_csv>")
+		flag.PrintDefaults()
 		return
 	}
-	graphPathStart := os.Args[1]
-	graphPathTerm := os.Args[2]
+	graphPathStart := flag.Arg(0)
+	graphPathTerm := flag.Arg(1)
+
+	rules := &board.Rules{MultiJump: *multiJump, Diagonal: *diagonal, Assignment: *assignment, Admissible: *admissible, Costs: board.Costs{Step: *step, Jump: *jump, Chain: *chain}}
 
-	start := board.NewBoardFromFile(graphPathStart)
-	terminal := board.NewBoardFromFile(graphPathTerm)
+	start, err := board.NewBoardFromFile(graphPathStart)
+	if err != nil {
+		println("can't load start board: " + err.Error())
+		return
+	}
+	start.SetRules(rules)
+	terminal, err := board.NewBoardFromFile(graphPathTerm)
+	if err != nil {
+		println("can't load terminal board: " + err.Error())
+		return
+	}
+	terminal.SetRules(rules)
+
+	switch {
+	case *genDbPath != "":
+		s := time.Now()
+		db := board.NewDatabase(*terminal, *pairs)
+		if err := db.Save(*genDbPath); err != nil {
+			println("can't save database: " + err.Error())
+			return
+		}
+		fmt.Printf("Database generated in %s\n", time.Since(s))
+		rules.Database = db
+	case *dbPath != "":
+		db, err := board.LoadDatabase(*dbPath)
+		if err != nil {
+			println("can't load database: " + err.Error())
+			return
+		}
+		if !db.Matches(*terminal) {
+			println("database was generated for another terminal board or rules")
+			return
+		}
+		rules.Database = db
+	}
 
 	fmt.Println("Start:")
 	start.Print()
@@ -25,8 +74,19 @@ func main() {
 	terminal.Print()
 	fmt.Println()
 
+	searches := map[string]func(board.Board, board.Board) ([]board.Board, int, bool){
+		"a":   a_search.A,
+		"ida": a_search.IDA,
+		"bi":  a_search.Bidirectional,
+	}
+	search, found := searches[*algo]
+	if !found {
+		println("unknown algorithm: " + *algo)
+		return
+	}
+
 	s := time.Now()
-	path, dur, ok := a_search.A(*start, *terminal)
+	path, cost, ok := search(*start, *terminal)
 	f := time.Since(s)
 
 	if ok {
@@ -35,7 +95,8 @@ func main() {
 			path[i].Print()
 			fmt.Println()
 		}
-		fmt.Printf("Len: %d\n", dur)
+		fmt.Printf("Len: %d\n", len(path)-1)
+		fmt.Printf("Cost: %d\n", cost)
 		fmt.Printf("Elapsed time: %s\n", f)
 	} else {
 		fmt.Println("Can't find solution")
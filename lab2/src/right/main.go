@@ -1,26 +1,166 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"os"
 	"right/sudoku"
 	"time"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		println("usage: ./main <path_to_csv>")
+	method := flag.String("method", "vnd", "solving method: vnd, anneal, tabu, genetic, beam or sbeam (stochastic beam)")
+	schedule := flag.String("schedule", "geometric", "cooling schedule of annealing: geometric, linear or adaptive")
+	temperature := flag.Float64("temp", sudoku.DefaultAnnealing.Temperature, "initial temperature of annealing, 0 to estimate it")
+	alpha := flag.Float64("alpha", sudoku.DefaultAnnealing.Alpha, "cooling factor of geometric and adaptive schedules")
+	levels := flag.Int("levels", sudoku.DefaultAnnealing.Levels, "number of temperature levels of annealing")
+	steps := flag.Int("steps", sudoku.DefaultAnnealing.Steps, "moves on every temperature level, 0 for number of non-static cells")
+	reheat := flag.Int("reheat", sudoku.DefaultAnnealing.Reheat, "levels without improvement before reheating of adaptive schedule")
+	tenure := flag.Int("tenure", sudoku.DefaultTabu.Tenure, "iterations during which cell can't get back its previous value in tabu search")
+	iterations := flag.Int("iters", sudoku.DefaultTabu.Iterations, "number of iterations of tabu search")
+	diversification := flag.Float64("diversify", sudoku.DefaultTabu.Diversification, "weight of penalty for frequent assignments in tabu search, 0 to disable")
+	population := flag.Int("pop", sudoku.DefaultGenetic.Population, "population size of genetic algorithm")
+	generations := flag.Int("gens", sudoku.DefaultGenetic.Generations, "number of generations of genetic algorithm")
+	elite := flag.Int("elite", sudoku.DefaultGenetic.Elite, "best individuals copied to next generation")
+	mutation := flag.Float64("mutation", sudoku.DefaultGenetic.MutationRate, "probability of mutation of child")
+	selection := flag.String("selection", "tournament", "parent selection of genetic algorithm: tournament or roulette")
+	tournament := flag.Int("tournament", sudoku.DefaultGenetic.TournamentSize, "number of individuals in tournament")
+	width := flag.Int("width", sudoku.DefaultBeam.Width, "number of states in beam")
+	beamIterations := flag.Int("beamiters", sudoku.DefaultBeam.Iterations, "number of iterations of beam search")
+	stall := flag.Int("stall", sudoku.DefaultBeam.Stall, "iterations without improvement before beam search stops, 0 to disable")
+	seed := flag.Int64("seed", sudoku.SEED, "seed of random generator")
+	progress := flag.Bool("progress", false, "print progress of anneal, tabu, genetic and beam methods")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		println("usage: ./main [flags] <path_to_csv>")
+		flag.PrintDefaults()
+		return
+	}
+	s, err := sudoku.NewSudoku(flag.Arg(0))
+	if err != nil {
+		println("can't load sudoku: " + err.Error())
 		return
 	}
-	s := sudoku.NewSudoku(os.Args[1])
 	fmt.Print("Unsolved sudoku:\n")
 	s.PrintSudoku(true)
 
 	start := time.Now()
-	solution := s.Solve()
+	var solution *sudoku.Sudoku
+	switch *method {
+	case "vnd":
+		solution = s.Solve()
+	case "anneal":
+		sch, err := sudoku.ParseSchedule(*schedule)
+		if err != nil {
+			println(err.Error())
+			return
+		}
+		cfg := sudoku.AnnealingConfig{
+			Schedule:    sch,
+			Temperature: *temperature,
+			Alpha:       *alpha,
+			Levels:      *levels,
+			Steps:       *steps,
+			Reheat:      *reheat,
+			Seed:        *seed,
+		}
+		if *progress {
+			cfg.Progress = func(level, h int, t float64) {
+				fmt.Printf("\033[1K\rLevel: %d     Heuristic: %3d     Temperature: %.4f", level, h, t)
+			}
+		}
+		var stats sudoku.AnnealingStats
+		solution, stats = s.Anneal(cfg)
+		endProgress(*progress)
+		fmt.Printf("Moves: %d, improving: %d, worse accepted: %d, rejected: %d, reheats: %d, levels: %d, best heuristic: %d\n",
+			stats.Moves, stats.Improved, stats.Worse, stats.Rejected, stats.Reheats, stats.Levels, stats.Best)
+	case "tabu":
+		cfg := sudoku.TabuConfig{
+			Tenure:          *tenure,
+			Iterations:      *iterations,
+			Diversification: *diversification,
+			Seed:            *seed,
+		}
+		if *progress {
+			cfg.Progress = func(iter, h, best int) {
+				fmt.Printf("\033[1K\rIteration: %d     Heuristic: %3d     Best: %3d", iter, h, best)
+			}
+		}
+		var stats sudoku.TabuStats
+		solution, stats = s.TabuSearch(cfg)
+		endProgress(*progress)
+		fmt.Printf("Iterations: %d, aspirations: %d, best heuristic: %d\n", stats.Iterations, stats.Aspirations, stats.Best)
+	case "genetic":
+		sel, err := sudoku.ParseSelection(*selection)
+		if err != nil {
+			println(err.Error())
+			return
+		}
+		cfg := sudoku.GeneticConfig{
+			Population:     *population,
+			Generations:    *generations,
+			Elite:          *elite,
+			MutationRate:   *mutation,
+			Selection:      sel,
+			TournamentSize: *tournament,
+			Seed:           *seed,
+		}
+		if *progress {
+			cfg.Progress = func(gen int, g sudoku.Generation) {
+				fmt.Printf("\033[1K\rGeneration: %d     Best: %3d     Average: %.2f", gen, g.Best, g.Average)
+			}
+		}
+		var history []sudoku.Generation
+		solution, history, err = s.Genetic(cfg)
+		endProgress(*progress)
+		if err != nil {
+			println(err.Error())
+			return
+		}
+		for i, g := range history {
+			if i%100 == 0 || i == len(history)-1 {
+				fmt.Printf("Generation %d: best heuristic %d, average %.2f\n", i, g.Best, g.Average)
+			}
+		}
+	case "beam", "sbeam":
+		cfg := sudoku.BeamConfig{
+			Width:      *width,
+			Iterations: *beamIterations,
+			Stall:      *stall,
+			Stochastic: *method == "sbeam",
+			Seed:       *seed,
+		}
+		if *progress {
+			cfg.Progress = func(iter, h, best int) {
+				fmt.Printf("\033[1K\rIteration: %d     Heuristic: %3d     Best: %3d", iter, h, best)
+			}
+		}
+		var stats sudoku.BeamStats
+		solution, stats, err = s.BeamSearch(cfg)
+		endProgress(*progress)
+		if err != nil {
+			println(err.Error())
+			return
+		}
+		fmt.Printf("Iterations: %d, expanded: %d, best heuristic: %d\n", stats.Iterations, stats.Expanded, stats.Best)
+	default:
+		println("unknown method: " + *method)
+		return
+	}
 	finish := time.Since(start)
 
 	fmt.Println("Time elapsed: ", finish)
-	fmt.Print("Solved sudoku:\n")
+	if c := solution.Conflicts(); c != 0 {
+		fmt.Printf("Can't solve, best found sudoku has %d conflicts:\n", c)
+	} else {
+		fmt.Print("Solved sudoku:\n")
+	}
 	solution.PrintSudoku(false)
 }
+
+// Move to new line after progress, which is overwritten in place
+func endProgress(progress bool) {
+	if progress {
+		fmt.Println()
+	}
+}
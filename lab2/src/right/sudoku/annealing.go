@@ -0,0 +1,156 @@
+package sudoku
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+type Schedule int
+
+const (
+	Geometric Schedule = iota // temperature is multiplied by Alpha on every level
+	Linear                    // temperature decreases to zero in Levels levels
+	Adaptive                  // geometric, temperature is reset when search gets stuck
+)
+
+func ParseSchedule(name string) (Schedule, error) {
+	switch name {
+	case "geometric":
+		return Geometric, nil
+	case "linear":
+		return Linear, nil
+	case "adaptive":
+		return Adaptive, nil
+	}
+
+	return 0, fmt.Errorf("unknown schedule %q, expected geometric, linear or adaptive", name)
+}
+
+// Parameters of simulated annealing
+type AnnealingConfig struct {
+	Schedule    Schedule
+	Temperature float64 // initial temperature, 0 to estimate it from random moves
+	Alpha       float64 // cooling factor of geometric and adaptive schedules
+	Levels      int     // number of temperature levels
+	Steps       int     // moves on every level, 0 for number of non-static cells
+	Reheat      int     // levels without improvement before reheating of adaptive schedule
+	Seed        int64
+	Progress    func(level, h int, t float64) // called before every temperature level if not nil
+}
+
+var DefaultAnnealing = AnnealingConfig{
+	Schedule: Geometric,
+	Alpha:    0.99,
+	Levels:   5000,
+	Reheat:   100,
+	Seed:     SEED,
+}
+
+// Acceptance statistics of simulated annealing
+type AnnealingStats struct {
+	Moves    int // tried moves
+	Improved int // accepted moves, which didn't make heuristic worse
+	Worse    int // accepted moves, which made heuristic worse
+	Rejected int
+	Reheats  int
+	Levels   int // passed temperature levels
+	Best     int // heuristic of the best state
+}
+
+// Simulated annealing over swaps of non-static cells inside of blocks, s is left unchanged.
+// Returns the best state found, it is solution if stats.Best is 0
+func (s *Sudoku) Anneal(cfg AnnealingConfig) (*Sudoku, AnnealingStats) {
+	r := rand.New(rand.NewSource(cfg.Seed))
+	s = s.copy()
+	s.initField()
+
+	h := s.heuristic()
+	best := s.copy()
+	stats := AnnealingStats{Best: h}
+	blocks := s.swappableBlocks()
+	if len(blocks) == 0 || h == 0 {
+		return best, stats
+	}
+
+	steps := cfg.Steps
+	if steps == 0 {
+		for _, cells := range blocks {
+			steps += len(cells)
+		}
+	}
+	t0 := cfg.Temperature
+	if t0 == 0 {
+		t0 = s.estimateTemperature(blocks, r)
+	}
+
+	t := t0
+	stuck := 0
+	for level := 0; level < cfg.Levels && h != 0; level++ {
+		if cfg.Progress != nil {
+			cfg.Progress(level, h, t)
+		}
+		improved := false
+
+		for i := 0; i < steps && h != 0; i++ {
+			a, b := randomSwap(blocks, r)
+			h1 := h + s.swapDelta(a, b)
+			stats.Moves++
+
+			switch {
+			case h1 <= h:
+				stats.Improved++
+			case r.Float64() < math.Exp(float64(h-h1)/t):
+				stats.Worse++
+			default:
+				stats.Rejected++
+				continue
+			}
+			s.swapCells(a, b)
+			h = h1
+			if h < stats.Best {
+				stats.Best = h
+				best = s.copy()
+				improved = true
+			}
+		}
+		stats.Levels++
+
+		switch cfg.Schedule {
+		case Geometric:
+			t *= cfg.Alpha
+		case Linear:
+			t = t0 * float64(cfg.Levels-level-1) / float64(cfg.Levels)
+		case Adaptive:
+			t *= cfg.Alpha
+			if improved {
+				stuck = 0
+			} else if stuck++; stuck >= cfg.Reheat {
+				t = t0
+				stuck = 0
+				stats.Reheats++
+			}
+		}
+		// Zero temperature means only non-worsening moves
+		t = math.Max(t, 1e-9)
+	}
+
+	return best, stats
+}
+
+// Standard deviation of heuristic over random walk, so that most of worse moves are accepted at start
+func (s *Sudoku) estimateTemperature(blocks [][]int, r *rand.Rand) float64 {
+	walk := s.copy()
+	const samples = 100
+	var sum, sumSq float64
+	for i := 0; i < samples; i++ {
+		a, b := randomSwap(blocks, r)
+		walk.swapCells(a, b)
+		h := float64(walk.counts.missing)
+		sum += h
+		sumSq += h * h
+	}
+	mean := sum / samples
+
+	return math.Max(math.Sqrt(sumSq/samples-mean*mean), 1)
+}
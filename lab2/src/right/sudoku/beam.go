@@ -0,0 +1,291 @@
+package sudoku
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Parameters of local beam search
+type BeamConfig struct {
+	Width      int  // number of states kept on every iteration
+	Iterations int  // budget of iterations
+	Stall      int  // iterations without improvement of the best state before search stops, 0 to disable
+	Stochastic bool // sample successors proportionally to fitness instead of keeping the best
+	Seed       int64
+	Progress   func(iter, h, best int) // called before every iteration if not nil
+}
+
+var DefaultBeam = BeamConfig{
+	Width:      10,
+	Iterations: 1000,
+	Stall:      200,
+	Seed:       SEED,
+}
+
+func (cfg BeamConfig) validate() error {
+	if cfg.Width < 1 {
+		return errors.New("beam width must be positive")
+	}
+
+	return nil
+}
+
+// Statistics of beam search
+type BeamStats struct {
+	Iterations int
+	Expanded   int // number of generated successors
+	Best       int // heuristic of the best state
+}
+
+// Local beam search: all successors of states in beam are generated and the best of them
+// (or random ones, the better the more likely, for stochastic search) form next beam.
+// Successors are scored on their parent and only those taken into beam are copied.
+// States which have already been in beam are not taken again, so beam can't cycle between local optima
+// and moves along plateaus instead. Stops when solution is found, iterations are over
+// or the best state isn't improved for cfg.Stall iterations.
+// Returns the best state found, it is solution if stats.Best is 0
+func (s *Sudoku) BeamSearch(cfg BeamConfig) (*Sudoku, BeamStats, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, BeamStats{}, err
+	}
+	r := rand.New(rand.NewSource(cfg.Seed))
+	moves := beamMoves(s.swappableBlocks())
+
+	// The first state is filled like in VND, others are random
+	visited := make(map[uint64]struct{})
+	beam := make([]individual, cfg.Width)
+	for i := range beam {
+		st := s.copy()
+		if i == 0 {
+			st.initField()
+		} else {
+			st.shake(r)
+		}
+		beam[i] = individual{s: st, h: st.counts.missing}
+	}
+	sortPopulation(beam)
+	z := newZobrist(s.size, r)
+	keys := make([]uint64, len(beam))
+	for i, ind := range beam {
+		keys[i] = z.hash(ind.s)
+		visited[keys[i]] = struct{}{}
+	}
+	best := beam[0]
+	stats := BeamStats{Best: best.h}
+
+	for iter, stall := 0, 0; iter < cfg.Iterations && best.h != 0 && len(moves) != 0; iter++ {
+		if cfg.Progress != nil {
+			cfg.Progress(iter, beam[0].h, best.h)
+		}
+
+		// Same states from different parents are taken once
+		seen := make(map[uint64]struct{})
+		var successors []successor
+		for i, ind := range beam {
+			for j, m := range moves {
+				h, key := ind.s.score(m, z, keys[i])
+				if _, ok := visited[key]; ok {
+					continue
+				}
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				successors = append(successors, successor{parent: i, move: j, h: h, key: key})
+			}
+		}
+		stats.Expanded += len(successors)
+		stats.Iterations++
+		if len(successors) == 0 {
+			break
+		}
+
+		if cfg.Stochastic {
+			h := make([]int, len(successors))
+			for i, succ := range successors {
+				h[i] = succ.h
+			}
+			chosen := make([]successor, 0, cfg.Width)
+			for _, i := range sample(h, cfg.Width, r) {
+				chosen = append(chosen, successors[i])
+			}
+			successors = chosen
+		}
+		sort.SliceStable(successors, func(i, j int) bool {
+			return successors[i].h < successors[j].h
+		})
+		successors = successors[:min(cfg.Width, len(successors))]
+
+		next := make([]individual, len(successors))
+		for i, succ := range successors {
+			st := beam[succ.parent].s.copy()
+			st.apply(moves[succ.move])
+			next[i] = individual{s: st, h: succ.h}
+			keys[i] = succ.key
+			visited[succ.key] = struct{}{}
+		}
+		beam, keys = next, keys[:len(next)]
+		if beam[0].h < best.h {
+			best = beam[0]
+			stats.Best = best.h
+			stall = 0
+		} else if stall++; cfg.Stall > 0 && stall >= cfg.Stall {
+			break
+		}
+	}
+
+	return best.s, stats, nil
+}
+
+// Successor of beam state, which isn't built until it is taken into beam
+type successor struct {
+	parent int // index of state in beam
+	move   int // index of move
+	h      int
+	key    uint64
+}
+
+// Move of VND operators: swap of cells a and b if cells is nil, reversal of cells otherwise
+type beamMove struct {
+	a, b  int
+	cells []int
+}
+
+// Moves of VND operators in every block: swap of two cells (swap),
+// reversal of cells between them (insert) and symmetric reversal around cell (megaswap)
+func beamMoves(blocks [][]int) []beamMove {
+	var res []beamMove
+	for _, cells := range blocks {
+		for m := 0; m < len(cells)-1; m++ {
+			for n := m + 1; n < len(cells); n++ {
+				res = append(res, beamMove{a: cells[m], b: cells[n]})
+				if n-m > 1 {
+					res = append(res, beamMove{cells: cells[m : n+1]})
+				}
+			}
+			if m > 0 {
+				// Reversal around m of the longest segment fitting into block
+				w := min(m, len(cells)-1-m)
+				res = append(res, beamMove{cells: cells[m-w : m+w+1]})
+			}
+		}
+	}
+
+	return res
+}
+
+func (s *Sudoku) apply(m beamMove) {
+	if m.cells == nil {
+		s.swapCells(m.a, m.b)
+		return
+	}
+	s.reverse(m.cells)
+}
+
+// Heuristic and key of state which move m turns s with key into.
+// Reversal is made and undone in place, so s is left unchanged
+func (s *Sudoku) score(m beamMove, z zobrist, key uint64) (int, uint64) {
+	if m.cells == nil {
+		return s.counts.missing + s.swapDelta(m.a, m.b), key ^ z.swap(s, m.a, m.b)
+	}
+
+	for i, j := 0, len(m.cells)-1; i < j; i, j = i+1, j-1 {
+		key ^= z.swap(s, m.cells[i], m.cells[j])
+	}
+	s.reverse(m.cells)
+	h := s.counts.missing
+	s.reverse(m.cells)
+
+	return h, key
+}
+
+// Random number for every value of every cell, state is keyed by xor of numbers of its values,
+// so key of successor is found from key of parent
+type zobrist struct {
+	size    int
+	numbers []uint64 // indexed by cell*(size+1) + value, 0 for empty cell
+}
+
+func newZobrist(size int, r *rand.Rand) zobrist {
+	z := zobrist{size: size, numbers: make([]uint64, size*size*(size+1))}
+	for i := range z.numbers {
+		z.numbers[i] = r.Uint64()
+	}
+
+	return z
+}
+
+func (z zobrist) hash(s *Sudoku) uint64 {
+	var res uint64
+	for idx := range s.field {
+		res ^= z.numbers[idx*(z.size+1)+s.valueIndex(idx)+1]
+	}
+
+	return res
+}
+
+// Change of key if cells a and b are swapped
+func (z zobrist) swap(s *Sudoku, a, b int) uint64 {
+	va, vb := s.valueIndex(a)+1, s.valueIndex(b)+1
+	if va == vb {
+		return 0
+	}
+
+	return z.numbers[a*(z.size+1)+va] ^ z.numbers[a*(z.size+1)+vb] ^
+		z.numbers[b*(z.size+1)+vb] ^ z.numbers[b*(z.size+1)+va]
+}
+
+// Sample k indices of states without replacement with probability proportional to fitness exp(-h),
+// it is shifted by the smallest heuristic to avoid underflow.
+// If weights of all remaining states underflow to 0, one of them is picked uniformly
+func sample(h []int, k int, r *rand.Rand) []int {
+	hMin := h[0]
+	for _, v := range h {
+		hMin = min(hMin, v)
+	}
+	weights := make([]float64, len(h))
+	var total float64
+	for i, v := range h {
+		weights[i] = math.Exp(float64(hMin - v))
+		total += weights[i]
+	}
+
+	var res []int
+	taken := make([]bool, len(h))
+	for len(res) < k && len(res) < len(h) {
+		chosen := -1
+		if total > 0 {
+			x := r.Float64() * total
+			for i, w := range weights {
+				if taken[i] || w == 0 {
+					continue
+				}
+				chosen = i
+				if x -= w; x <= 0 {
+					break
+				}
+			}
+		}
+		if chosen == -1 {
+			// Pick n-th of states which aren't taken yet
+			n := r.Intn(len(h) - len(res))
+			for i := range taken {
+				if taken[i] {
+					continue
+				}
+				if n == 0 {
+					chosen = i
+					break
+				}
+				n--
+			}
+		}
+		res = append(res, chosen)
+		taken[chosen] = true
+		total -= weights[chosen]
+	}
+
+	return res
+}
@@ -0,0 +1,28 @@
+package sudoku
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSampleUnderflow(t *testing.T) {
+	// Weights of all states but the first underflow to 0
+	h := []int{0, 2000, 3000, 4000}
+	r := rand.New(rand.NewSource(1))
+	for k := 1; k <= len(h)+1; k++ {
+		res := sample(h, k, r)
+		if len(res) != min(k, len(h)) {
+			t.Fatalf("k = %d: got %d states", k, len(res))
+		}
+		taken := make(map[int]bool)
+		for _, i := range res {
+			if i < 0 || i >= len(h) || taken[i] {
+				t.Fatalf("k = %d: got %v", k, res)
+			}
+			taken[i] = true
+		}
+		if !taken[0] {
+			t.Fatalf("k = %d: the best state isn't taken in %v", k, res)
+		}
+	}
+}
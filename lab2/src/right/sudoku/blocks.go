@@ -0,0 +1,75 @@
+package sudoku
+
+import "math/rand"
+
+// Indexes of non-static cells of every block, blocks with less than two such cells are skipped
+func (s *Sudoku) swappableBlocks() [][]int {
+	var res [][]int
+	for i := 0; i < s.size/s.boxHeight; i++ {
+		for j := 0; j < s.size/s.boxWidth; j++ {
+			if cells := s.freeCells(i, j); len(cells) > 1 {
+				res = append(res, cells)
+			}
+		}
+	}
+
+	return res
+}
+
+// Indexes of non-static cells of block in block row i and block column j
+func (s *Sudoku) freeCells(i, j int) []int {
+	var res []int
+	for k := 0; k < s.boxHeight; k++ {
+		for l := 0; l < s.boxWidth; l++ {
+			if idx := i*s.boxHeight*s.size + k*s.size + j*s.boxWidth + l; !s.static[idx] {
+				res = append(res, idx)
+			}
+		}
+	}
+
+	return res
+}
+
+// Two different non-static cells of random block
+func randomSwap(blocks [][]int, r *rand.Rand) (int, int) {
+	cells := blocks[r.Intn(len(blocks))]
+	a := r.Intn(len(cells))
+	b := r.Intn(len(cells) - 1)
+	if b >= a {
+		b++
+	}
+
+	return cells[a], cells[b]
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Swap values of cells, counters are updated if they are used
+func (s *Sudoku) swapCells(a, b int) {
+	if s.counts != nil {
+		s.counts.swap(s, a, b)
+	}
+	s.field[a], s.field[b] = s.field[b], s.field[a]
+}
+
+// Reverse order of values of cells
+func (s *Sudoku) reverse(cells []int) {
+	for i, j := 0, len(cells)-1; i < j; i, j = i+1, j-1 {
+		s.swapCells(cells[i], cells[j])
+	}
+}
+
+func (s *Sudoku) copy() *Sudoku {
+	res := &Sudoku{size: s.size, boxHeight: s.boxHeight, boxWidth: s.boxWidth, static: s.static}
+	res.field = append(res.field, s.field...)
+	if s.counts != nil {
+		res.counts = s.counts.copy()
+	}
+
+	return res
+}
@@ -0,0 +1,104 @@
+package sudoku
+
+// Number of occurrences of every value in every row and column, kept in sync with field,
+// so that effect of swap on heuristic is found without rescanning the grid
+type counters struct {
+	rows    []int // indexed by row*size + value-1
+	columns []int // indexed by column*size + value-1
+	missing int   // values missing in rows and columns, equals heuristic()
+}
+
+func (s *Sudoku) initCounters() {
+	c := &counters{rows: make([]int, s.size*s.size), columns: make([]int, s.size*s.size)}
+	for idx, v := range s.field {
+		if v == 0 {
+			continue
+		}
+		n := getIntFromBinary(v, s.size) - 1
+		c.rows[idx/s.size*s.size+n]++
+		c.columns[idx%s.size*s.size+n]++
+	}
+	for i := range c.rows {
+		if c.rows[i] == 0 {
+			c.missing++
+		}
+		if c.columns[i] == 0 {
+			c.missing++
+		}
+	}
+
+	s.counts = c
+}
+
+// Index of value of cell in counters, -1 for empty cell
+func (s *Sudoku) valueIndex(idx int) int {
+	return getIntFromBinary(s.field[idx], s.size) - 1
+}
+
+// Change of heuristic if cells a and b are swapped
+func (s *Sudoku) swapDelta(a, b int) int {
+	va, vb := s.valueIndex(a), s.valueIndex(b)
+	if va == vb {
+		return 0
+	}
+
+	var d int
+	if ra, rb := a/s.size, b/s.size; ra != rb {
+		d += lineDelta(s.counts.rows[ra*s.size:], va, vb) + lineDelta(s.counts.rows[rb*s.size:], vb, va)
+	}
+	if ca, cb := a%s.size, b%s.size; ca != cb {
+		d += lineDelta(s.counts.columns[ca*s.size:], va, vb) + lineDelta(s.counts.columns[cb*s.size:], vb, va)
+	}
+
+	return d
+}
+
+// Change of missing values of line, which loses value out and gets value in
+func lineDelta(line []int, out, in int) int {
+	var d int
+	if out >= 0 && line[out] == 1 {
+		d++
+	}
+	if in >= 0 && line[in] == 0 {
+		d--
+	}
+
+	return d
+}
+
+// Update counters of cells a and b, which are being swapped
+func (c *counters) swap(s *Sudoku, a, b int) {
+	va, vb := s.valueIndex(a), s.valueIndex(b)
+	if va == vb {
+		return
+	}
+	if ra, rb := a/s.size, b/s.size; ra != rb {
+		c.move(c.rows[ra*s.size:], va, vb)
+		c.move(c.rows[rb*s.size:], vb, va)
+	}
+	if ca, cb := a%s.size, b%s.size; ca != cb {
+		c.move(c.columns[ca*s.size:], va, vb)
+		c.move(c.columns[cb*s.size:], vb, va)
+	}
+}
+
+func (c *counters) move(line []int, out, in int) {
+	if out >= 0 {
+		if line[out]--; line[out] == 0 {
+			c.missing++
+		}
+	}
+	if in >= 0 {
+		if line[in]++; line[in] == 1 {
+			c.missing--
+		}
+	}
+}
+
+func (c *counters) copy() *counters {
+	res := &counters{missing: c.missing}
+	res.rows = append(res.rows, c.rows...)
+	res.columns = append(res.columns, c.columns...)
+
+	return res
+}
@@ -0,0 +1,38 @@
+package sudoku
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+)
+
+func TestCountersMatchHeuristic(t *testing.T) {
+	for _, path := range []string{"../test/sudoku4.csv", "../test/sudoku6.csv", "../test/sudoku9_hard.csv", "../test/sudoku16.csv"} {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := ReadSudoku(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+
+		r := rand.New(rand.NewSource(1))
+		s.initField()
+		blocks := s.swappableBlocks()
+		for i := 0; i < 1000; i++ {
+			switch i % 10 {
+			case 0:
+				s.shake(r)
+			case 1, 2, 3:
+				s.reverse(blocks[r.Intn(len(blocks))])
+			default:
+				s.swapCells(randomSwap(blocks, r))
+			}
+			if s.counts.missing != s.heuristic() {
+				t.Fatalf("%s: after %d moves counters give %d, heuristic is %d", path, i+1, s.counts.missing, s.heuristic())
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package sudoku
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+type Selection int
+
+const (
+	Tournament Selection = iota
+	Roulette
+)
+
+func ParseSelection(name string) (Selection, error) {
+	switch name {
+	case "tournament":
+		return Tournament, nil
+	case "roulette":
+		return Roulette, nil
+	}
+
+	return 0, fmt.Errorf("unknown selection %q, expected tournament or roulette", name)
+}
+
+// Parameters of genetic algorithm
+type GeneticConfig struct {
+	Population     int
+	Generations    int // budget of generations
+	Elite          int // best individuals copied to next generation unchanged
+	MutationRate   float64
+	Selection      Selection
+	TournamentSize int
+	Seed           int64
+	Progress       func(gen int, g Generation) // called after every generation if not nil
+}
+
+var DefaultGenetic = GeneticConfig{
+	Population:     100,
+	Generations:    5000,
+	Elite:          5,
+	MutationRate:   0.3,
+	Selection:      Tournament,
+	TournamentSize: 3,
+	Seed:           SEED,
+}
+
+func (cfg GeneticConfig) validate() error {
+	switch {
+	case cfg.Population < 1:
+		return errors.New("population must be positive")
+	case cfg.Elite < 0 || cfg.Elite > cfg.Population:
+		return errors.New("elite must be from 0 to population")
+	case cfg.Selection == Tournament && cfg.TournamentSize < 1:
+		return errors.New("tournament size must be positive")
+	}
+
+	return nil
+}
+
+// Heuristic of population in one generation
+type Generation struct {
+	Best    int
+	Average float64
+}
+
+type individual struct {
+	s *Sudoku
+	h int
+}
+
+// Genetic algorithm over grids with consistent blocks. Child takes every block from one of parents,
+// mutation swaps non-static cells inside of block, so givens and blocks are never broken.
+// Stops when solution is found or generations are over, returns the best individual and history of generations
+func (s *Sudoku) Genetic(cfg GeneticConfig) (*Sudoku, []Generation, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, nil, err
+	}
+	r := rand.New(rand.NewSource(cfg.Seed))
+	blocks := s.swappableBlocks()
+
+	population := make([]individual, cfg.Population)
+	for i := range population {
+		ind := s.copy()
+		ind.shake(r)
+		population[i] = individual{s: ind, h: ind.counts.missing}
+	}
+	sortPopulation(population)
+
+	var history []Generation
+	for gen := 0; ; gen++ {
+		sum := 0
+		for _, ind := range population {
+			sum += ind.h
+		}
+		history = append(history, Generation{Best: population[0].h, Average: float64(sum) / float64(len(population))})
+		if cfg.Progress != nil {
+			cfg.Progress(gen, history[gen])
+		}
+		if population[0].h == 0 || gen == cfg.Generations || len(blocks) == 0 {
+			break
+		}
+
+		next := make([]individual, 0, len(population))
+		for i := 0; i < cfg.Elite && i < len(population); i++ {
+			next = append(next, population[i])
+		}
+		for len(next) < len(population) {
+			a, b := population[selectParent(population, cfg, r)], population[selectParent(population, cfg, r)]
+			child := crossover(a.s, b.s, blocks, r)
+			if r.Float64() < cfg.MutationRate {
+				child.swapCells(randomSwap(blocks, r))
+			}
+			next = append(next, individual{s: child, h: child.counts.missing})
+		}
+		population = next
+		sortPopulation(population)
+	}
+
+	return population[0].s, history, nil
+}
+
+func sortPopulation(population []individual) {
+	sort.SliceStable(population, func(i, j int) bool {
+		return population[i].h < population[j].h
+	})
+}
+
+// Index of parent chosen by tournament or roulette, population is sorted by heuristic
+func selectParent(population []individual, cfg GeneticConfig, r *rand.Rand) int {
+	if cfg.Selection == Roulette {
+		// Fitness of individual is inverse to heuristic
+		var total float64
+		for _, ind := range population {
+			total += 1 / float64(1+ind.h)
+		}
+		x := r.Float64() * total
+		for i, ind := range population {
+			if x -= 1 / float64(1+ind.h); x <= 0 {
+				return i
+			}
+		}
+		return len(population) - 1
+	}
+
+	// The best of random individuals wins, population is sorted so the smallest index is the best
+	res := r.Intn(len(population))
+	for i := 1; i < cfg.TournamentSize; i++ {
+		if j := r.Intn(len(population)); j < res {
+			res = j
+		}
+	}
+
+	return res
+}
+
+// Child takes every block from random parent
+func crossover(a, b *Sudoku, blocks [][]int, r *rand.Rand) *Sudoku {
+	child := a.copy()
+	for _, cells := range blocks {
+		if r.Intn(2) == 0 {
+			continue
+		}
+		for _, c := range cells {
+			child.field[c] = b.field[c]
+		}
+	}
+	child.initCounters()
+
+	return child
+}
@@ -3,57 +3,134 @@ package sudoku
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
 	"os"
 	"strconv"
+	"strings"
 )
 
 const SEED = 123
 
+// Max size of sudoku, which cells fit into bitmask
+const MaxSize = 64
+
+// One-hot value of cell, value n is bit n-1
+type bitmask = uint64
+
 type Sudoku struct {
-	size    int
-	subSize int
-	field   []uint32
+	size      int
+	boxHeight int
+	boxWidth  int
+	field     []bitmask
+	static    []bool    // cells given in puzzle, shared by copies of sudoku
+	counts    *counters // counters of values, set when field is filled
 }
 
-func NewSudoku(path string) *Sudoku {
+func NewSudoku(path string) (*Sudoku, error) {
 	csvConf, err := os.Open(path)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	defer func() {
-		if err = csvConf.Close(); err != nil {
-			panic(err)
-		}
-	}()
+	defer csvConf.Close()
+
+	return ReadSudoku(csvConf)
+}
 
-	reader := csv.NewReader(csvConf)
+// Read sudoku from CSV: size in the first line, optionally followed by box height and width,
+// then rows of values separated by spaces, 0 for empty cell
+func ReadSudoku(r io.Reader) (*Sudoku, error) {
+	reader := csv.NewReader(r)
 	reader.Comma = ' '
 	reader.TrimLeadingSpace = true
 	reader.FieldsPerRecord = -1
-	data, err := reader.ReadAll()
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, &ParseError{Line: 1, Column: 1, Err: ErrSize}
+	}
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	line, column := reader.FieldPos(0)
+	size, boxHeight, boxWidth, err := parseHeader(header)
+	if err != nil {
+		return nil, &ParseError{Line: line, Column: column, Err: err}
+	}
+
+	sudoku := &Sudoku{size: size, boxHeight: boxHeight, boxWidth: boxWidth, field: make([]bitmask, size*size), static: make([]bool, size*size)}
+	for i := 0; ; i++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			if i != size {
+				return nil, &ParseError{Line: line + 1, Column: 1, Err: ErrRows}
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, column = reader.FieldPos(0)
+		// Skip trailing spaces
+		for len(row) > 1 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		if i == size {
+			return nil, &ParseError{Line: line, Column: column, Err: ErrRows}
+		}
+		if len(row) != size {
+			return nil, &ParseError{Line: line, Column: column, Err: ErrColumns}
+		}
 
-	size, _ := strconv.Atoi(data[0][0])
-	sudoku := &Sudoku{size: size, subSize: int(math.Sqrt(float64(size))), field: make([]uint32, size*size)}
-	data = data[1:]
-	var isFilled bool
-	for i, row := range data {
 		for j, el := range row {
-			val, _ := strconv.Atoi(el)
-			if val != 0 {
-				isFilled = true
-			} else {
-				isFilled = false
+			val, err := strconv.Atoi(el)
+			if err != nil || val < 0 || val > size {
+				line, column = reader.FieldPos(j)
+				return nil, &ParseError{Line: line, Column: column, Err: fmt.Errorf("%w, got %q", ErrValue, el)}
 			}
-			sudoku.field[i*size+j] = getBinaryFromInt(val, isFilled, size)
+			sudoku.field[i*size+j] = getBinaryFromInt(val, size)
+			sudoku.static[i*size+j] = val != 0
+		}
+	}
+
+	return sudoku, nil
+}
+
+// Size, box height and box width from CSV header. Without box size boxes are square
+func parseHeader(header []string) (int, int, int, error) {
+	// Skip trailing spaces
+	for len(header) > 1 && header[len(header)-1] == "" {
+		header = header[:len(header)-1]
+	}
+	var dims []int
+	for _, el := range header {
+		n, err := strconv.Atoi(el)
+		if err != nil {
+			return 0, 0, 0, ErrSize
+		}
+		dims = append(dims, n)
+	}
+
+	size := dims[0]
+	if size < 1 || size > MaxSize {
+		return 0, 0, 0, ErrSize
+	}
+	switch len(dims) {
+	case 1:
+		box := int(math.Sqrt(float64(size)))
+		if box*box != size {
+			return 0, 0, 0, ErrSize
+		}
+		return size, box, box, nil
+	case 3:
+		if dims[1] < 1 || dims[2] < 1 || dims[1]*dims[2] != size {
+			return 0, 0, 0, ErrBox
 		}
+		return size, dims[1], dims[2], nil
 	}
 
-	return sudoku
+	return 0, 0, 0, ErrSize
 }
 
 func (s *Sudoku) Solve() *Sudoku {
@@ -75,8 +152,8 @@ func (s *Sudoku) Solve() *Sudoku {
 		var best *Sudoku
 		oldH := h
 		for i := 0; i < neighbourBlocks; i++ {
-			s1 := methods[currMethod](i%s.subSize, i/s.subSize, h)
-			if h1 := s1.heuristic(); h1 < h {
+			s1 := methods[currMethod](i%(s.size/s.boxHeight), i/(s.size/s.boxHeight), h)
+			if h1 := s1.counts.missing; h1 < h {
 				best = s1
 				h = h1
 			}
@@ -96,9 +173,9 @@ func (s *Sudoku) Solve() *Sudoku {
 				}
 			}
 		} else {
-			s.field = best.field
+			s.field, s.counts = best.field, best.counts
 		}
-		h = s.heuristic()
+		h = s.counts.missing
 		fmt.Print("\033[1K\r")
 	}
 
@@ -108,28 +185,28 @@ func (s *Sudoku) Solve() *Sudoku {
 }
 
 func (s *Sudoku) shake(r *rand.Rand) {
-	for i := 0; i < s.subSize; i++ {
-		for j := 0; j < s.subSize; j++ {
+	for i := 0; i < s.size/s.boxHeight; i++ {
+		for j := 0; j < s.size/s.boxWidth; j++ {
 			alreadyInserted := make(map[int]struct{})
-			for k := 0; k < s.subSize; k++ {
-				for l := 0; l < s.subSize; l++ {
-					if isStatic(s.field[i*s.subSize*s.size+k*s.size+j*s.subSize+l], s.size) {
-						alreadyInserted[getIntFromBinary(s.field[i*s.subSize*s.size+k*s.size+j*s.subSize+l], s.size)] = struct{}{}
+			for k := 0; k < s.boxHeight; k++ {
+				for l := 0; l < s.boxWidth; l++ {
+					if s.static[i*s.boxHeight*s.size+k*s.size+j*s.boxWidth+l] {
+						alreadyInserted[getIntFromBinary(s.field[i*s.boxHeight*s.size+k*s.size+j*s.boxWidth+l], s.size)] = struct{}{}
 					}
 				}
 			}
 			//count := 1
-			for k := 0; k < s.subSize; k++ {
-				for l := 0; l < s.subSize; l++ {
+			for k := 0; k < s.boxHeight; k++ {
+				for l := 0; l < s.boxWidth; l++ {
 
-					if isStatic(s.field[i*s.subSize*s.size+k*s.size+j*s.subSize+l], s.size) {
+					if s.static[i*s.boxHeight*s.size+k*s.size+j*s.boxWidth+l] {
 						continue
 					}
 					for {
 						v := r.Int()%s.size + 1
 						_, exists := alreadyInserted[v]
 						if !exists {
-							s.field[i*s.subSize*s.size+k*s.size+j*s.subSize+l] = getBinaryFromInt(v, false, s.size)
+							s.field[i*s.boxHeight*s.size+k*s.size+j*s.boxWidth+l] = getBinaryFromInt(v, s.size)
 							alreadyInserted[v] = struct{}{}
 							break
 						}
@@ -138,12 +215,13 @@ func (s *Sudoku) shake(r *rand.Rand) {
 			}
 		}
 	}
+	s.initCounters()
 }
 
 // Invert
 func (s *Sudoku) invert(i, j int) *Sudoku {
 	// Get copy of field
-	res := &Sudoku{size: s.size, subSize: s.subSize, field: make([]uint32, 0)}
+	res := &Sudoku{size: s.size, boxHeight: s.boxHeight, boxWidth: s.boxWidth, static: s.static, field: make([]bitmask, 0)}
 	res.field = append(res.field, s.field...)
 
 	// Get indexes of non-fixed elements
@@ -151,9 +229,9 @@ func (s *Sudoku) invert(i, j int) *Sudoku {
 		k int
 		l int
 	}, 0)
-	for k := 0; k < s.subSize; k++ {
-		for l := 0; l < s.subSize; l++ {
-			if !isStatic(s.field[i*s.subSize*s.size+k*s.size+j*s.subSize+l], s.size) {
+	for k := 0; k < s.boxHeight; k++ {
+		for l := 0; l < s.boxWidth; l++ {
+			if !s.static[i*s.boxHeight*s.size+k*s.size+j*s.boxWidth+l] {
 				a = append(a, struct {
 					k int
 					l int
@@ -168,9 +246,9 @@ func (s *Sudoku) invert(i, j int) *Sudoku {
 	for start < finish {
 		sk, sl := a[start].k, a[start].l
 		fk, fl := a[finish].k, a[finish].l
-		tmp := res.field[i*s.subSize*s.size+sk*s.size+j*s.subSize+sl]
-		res.field[i*s.subSize*s.size+sk*s.size+j*s.subSize+sl] = res.field[i*s.subSize*s.size+fk*s.size+j*s.subSize+fl]
-		res.field[i*s.subSize*s.size+fk*s.size+j*s.subSize+fl] = tmp
+		tmp := res.field[i*s.boxHeight*s.size+sk*s.size+j*s.boxWidth+sl]
+		res.field[i*s.boxHeight*s.size+sk*s.size+j*s.boxWidth+sl] = res.field[i*s.boxHeight*s.size+fk*s.size+j*s.boxWidth+fl]
+		res.field[i*s.boxHeight*s.size+fk*s.size+j*s.boxWidth+fl] = tmp
 		start++
 		finish--
 	}
@@ -178,43 +256,17 @@ func (s *Sudoku) invert(i, j int) *Sudoku {
 	return res
 }
 
+// Reverse cells between every pair of non-static cells of block, reversal is kept if it gives heuristic below target
 func (s *Sudoku) insert(i, j int, target int) *Sudoku {
-	a := make([]struct {
-		k int
-		l int
-	}, 0)
-	for k := 0; k < s.subSize; k++ {
-		for l := 0; l < s.subSize; l++ {
-			if !isStatic(s.field[i*s.subSize*s.size+k*s.size+j*s.subSize+l], s.size) {
-				a = append(a, struct {
-					k int
-					l int
-				}{k: k, l: l})
-			}
-		}
-	}
-
-	res := &Sudoku{size: s.size, subSize: s.subSize}
-	res.field = append(res.field, s.field...)
-	tmp := &Sudoku{size: s.size, subSize: s.subSize}
-
-	for m := 0; m < len(a)-1; m++ {
-		for n := m + 1; n < len(a); n++ {
-			tmp.field = make([]uint32, 0)
-			tmp.field = append(tmp.field, res.field...)
-			start, finish := m, n
-			for start < finish {
-				sk, sl := a[start].k, a[start].l
-				fk, fl := a[finish].k, a[finish].l
-				t := tmp.field[i*s.subSize*s.size+sk*s.size+j*s.subSize+sl]
-				tmp.field[i*s.subSize*s.size+sk*s.size+j*s.subSize+sl] =
-					tmp.field[i*s.subSize*s.size+fk*s.size+j*s.subSize+fl]
-				tmp.field[i*s.subSize*s.size+fk*s.size+j*s.subSize+fl] = t
-				start++
-				finish--
-			}
-			if tmp.heuristic() < target {
-				res.field = tmp.field
+	cells := s.freeCells(i, j)
+	res := s.copy()
+
+	for m := 0; m < len(cells)-1; m++ {
+		for n := m + 1; n < len(cells); n++ {
+			res.reverse(cells[m : n+1])
+			if res.counts.missing >= target {
+				// Undo
+				res.reverse(cells[m : n+1])
 			}
 		}
 	}
@@ -222,114 +274,72 @@ func (s *Sudoku) insert(i, j int, target int) *Sudoku {
 	return res
 }
 
+// Swap two non-static cells of block, the last swap giving heuristic below target is made
 func (s *Sudoku) swap(i, j int, target int) *Sudoku {
-	a := make([]struct {
-		k int
-		l int
-	}, 0)
-	for k := 0; k < s.subSize; k++ {
-		for l := 0; l < s.subSize; l++ {
-			if !isStatic(s.field[i*s.subSize*s.size+k*s.size+j*s.subSize+l], s.size) {
-				a = append(a, struct {
-					k int
-					l int
-				}{k: k, l: l})
+	cells := s.freeCells(i, j)
+	res := s.copy()
+
+	a, b := -1, -1
+	for m := 0; m < len(cells)-1; m++ {
+		for n := m + 1; n < len(cells); n++ {
+			if s.counts.missing+s.swapDelta(cells[m], cells[n]) < target {
+				a, b = cells[m], cells[n]
 			}
 		}
 	}
-
-	// Create copy of field
-	res := &Sudoku{size: s.size, subSize: s.subSize}
-	res.field = append(res.field, s.field...)
-	tmp := &Sudoku{size: s.size, subSize: s.subSize}
-
-	for m := 0; m < len(a)-1; m++ {
-		for n := m + 1; n < len(a); n++ {
-			tmp.field = make([]uint32, 0)
-			tmp.field = append(tmp.field, s.field...)
-			start, finish := m, n
-			sk, sl := a[start].k, a[start].l
-			fk, fl := a[finish].k, a[finish].l
-			t := tmp.field[i*s.subSize*s.size+sk*s.size+j*s.subSize+sl]
-			tmp.field[i*s.subSize*s.size+sk*s.size+j*s.subSize+sl] =
-				tmp.field[i*s.subSize*s.size+fk*s.size+j*s.subSize+fl]
-			tmp.field[i*s.subSize*s.size+fk*s.size+j*s.subSize+fl] = t
-			if tmp.heuristic() < target {
-				res.field = tmp.field
-			}
-		}
+	if a != -1 {
+		res.swapCells(a, b)
 	}
 
 	return res
 }
 
+// Reverse cells symmetrically around every non-static cell of block,
+// the last reversal giving heuristic below target is made
 func (s *Sudoku) megaswap(i, j int, target int) *Sudoku {
-	a := make([]struct {
-		k int
-		l int
-	}, 0)
-	for k := 0; k < s.subSize; k++ {
-		for l := 0; l < s.subSize; l++ {
-			if !isStatic(s.field[i*s.subSize*s.size+k*s.size+j*s.subSize+l], s.size) {
-				a = append(a, struct {
-					k int
-					l int
-				}{k: k, l: l})
-			}
-		}
-	}
-
-	res := &Sudoku{size: s.size, subSize: s.subSize}
-	res.field = append(res.field, s.field...)
-	tmp := &Sudoku{size: s.size, subSize: s.subSize}
-
-	for m := 1; m < len(a)-1; m++ {
-		tmp.field = make([]uint32, 0)
-		tmp.field = append(tmp.field, s.field...)
-		start, finish := m-1, m+1
-		for start >= 0 && finish <= len(a)-1 {
-			sk, sl := a[start].k, a[start].l
-			fk, fl := a[finish].k, a[finish].l
-			t := tmp.field[i*s.subSize*s.size+sk*s.size+j*s.subSize+sl]
-			tmp.field[i*s.subSize*s.size+sk*s.size+j*s.subSize+sl] =
-				tmp.field[i*s.subSize*s.size+fk*s.size+j*s.subSize+fl]
-			tmp.field[i*s.subSize*s.size+fk*s.size+j*s.subSize+fl] = t
-			start--
-			finish++
-		}
-		if tmp.heuristic() < target {
-			res.field = tmp.field
+	cells := s.freeCells(i, j)
+	res := s.copy()
+
+	var segment []int
+	for m := 1; m < len(cells)-1; m++ {
+		w := min(m, len(cells)-1-m)
+		// Reversal is tried in place and undone
+		s.reverse(cells[m-w : m+w+1])
+		if s.counts.missing < target {
+			segment = cells[m-w : m+w+1]
 		}
+		s.reverse(cells[m-w : m+w+1])
 	}
+	res.reverse(segment)
 
 	return res
 }
 
 // Fill empty spaces to satisfy sub-grid constraint
 func (s *Sudoku) initField() {
-	for i := 0; i < s.subSize; i++ {
-		for j := 0; j < s.subSize; j++ {
+	for i := 0; i < s.size/s.boxHeight; i++ {
+		for j := 0; j < s.size/s.boxWidth; j++ {
 			alreadyInserted := make(map[int]struct{})
-			for k := 0; k < s.subSize; k++ {
-				for l := 0; l < s.subSize; l++ {
-					v := getIntFromBinary(s.field[i*s.subSize*s.size+k*s.size+j*s.subSize+l], s.size)
+			for k := 0; k < s.boxHeight; k++ {
+				for l := 0; l < s.boxWidth; l++ {
+					v := getIntFromBinary(s.field[i*s.boxHeight*s.size+k*s.size+j*s.boxWidth+l], s.size)
 					if v != 0 {
 						alreadyInserted[v] = struct{}{}
 					}
 				}
 			}
 			count := 1
-			for k := 0; k < s.subSize; k++ {
-				for l := 0; l < s.subSize; l++ {
+			for k := 0; k < s.boxHeight; k++ {
+				for l := 0; l < s.boxWidth; l++ {
 
-					v := getIntFromBinary(s.field[i*s.subSize*s.size+k*s.size+j*s.subSize+l], s.size)
+					v := getIntFromBinary(s.field[i*s.boxHeight*s.size+k*s.size+j*s.boxWidth+l], s.size)
 					if v != 0 {
 						continue
 					}
 					for {
 						_, exists := alreadyInserted[count]
 						if !exists {
-							s.field[i*s.subSize*s.size+k*s.size+j*s.subSize+l] = getBinaryFromInt(count, false, s.size)
+							s.field[i*s.boxHeight*s.size+k*s.size+j*s.boxWidth+l] = getBinaryFromInt(count, s.size)
 							alreadyInserted[count] = struct{}{}
 							break
 						}
@@ -339,11 +349,12 @@ func (s *Sudoku) initField() {
 			}
 		}
 	}
+	s.initCounters()
 }
 
 func (s *Sudoku) heuristic() int {
 	var res int
-	var mask uint32
+	var mask bitmask
 	for i := 0; i < s.size; i++ {
 		mask <<= 1
 		mask++
@@ -351,7 +362,7 @@ func (s *Sudoku) heuristic() int {
 
 	// horizontal
 	for i := 0; i < s.size; i++ {
-		var heuristic uint32
+		var heuristic bitmask
 		for j := 0; j < s.size; j++ {
 			heuristic |= s.field[i*s.size+j]
 		}
@@ -360,7 +371,7 @@ func (s *Sudoku) heuristic() int {
 
 	// vertical
 	for j := 0; j < s.size; j++ {
-		var heuristic uint32
+		var heuristic bitmask
 		for i := 0; i < s.size; i++ {
 			heuristic |= s.field[i*s.size+j]
 		}
@@ -370,49 +381,48 @@ func (s *Sudoku) heuristic() int {
 	return res
 }
 
+// Number of values missing in rows and columns, 0 for solved sudoku
+func (s *Sudoku) Conflicts() int {
+	return s.heuristic()
+}
+
 func (s *Sudoku) PrintSudoku(isUnsolved bool) {
+	// Cells are widened for multi-digit values
+	width := len(strconv.Itoa(s.size))
+	if width < 2 {
+		width = 2
+	}
+	line := " " + strings.Repeat("-", s.size*(width+1)+2*(s.size/s.boxWidth)+1)
+
 	for i := 0; i < s.size; i++ {
-		if i%s.subSize == 0 {
-			fmt.Print(" ")
-			for k := 0; k < (s.size+1)*3+s.subSize+1; k++ {
-				fmt.Printf("-")
-			}
-			fmt.Println()
+		if i%s.boxHeight == 0 {
+			fmt.Println(line)
 		}
 		for j := 0; j < s.size; j++ {
-			if j%s.subSize == 0 {
+			if j%s.boxWidth == 0 {
 				fmt.Print(" |")
 			}
 			n := getIntFromBinary(s.field[i*s.size+j], s.size)
-			if isStatic(s.field[i*s.size+j], s.size) {
+			if s.static[i*s.size+j] {
 				fmt.Print("\033[32m") // green
 			}
-			if isUnsolved {
-				if n == 0 {
-					fmt.Printf("  *")
-				} else {
-					fmt.Printf(" %2d", n)
-				}
+			if isUnsolved && n == 0 {
+				fmt.Printf(" %*s", width, "*")
 			} else {
-				fmt.Printf(" %2d", n)
+				fmt.Printf(" %*d", width, n)
 			}
-			if isStatic(s.field[i*s.size+j], s.size) {
+			if s.static[i*s.size+j] {
 				fmt.Print("\033[0m") // green
 			}
 		}
 		fmt.Print(" |")
 		fmt.Print("\n")
 	}
-	fmt.Print(" ")
-	for k := 0; k < (s.size+1)*3+s.subSize+1; k++ {
-		fmt.Printf("-")
-	}
-	fmt.Println()
+	fmt.Println(line)
 }
 
 // Functions to work with binary
-func getIntFromBinary(b uint32, max int) int {
-	b &= ^(1 << max)
+func getIntFromBinary(b bitmask, max int) int {
 	var res int
 	for b != 0 {
 		res++
@@ -422,34 +432,29 @@ func getIntFromBinary(b uint32, max int) int {
 	return res
 }
 
-func getBinaryFromInt(n int, isStatic bool, max int) uint32 {
+func getBinaryFromInt(n int, max int) bitmask {
 	if n == 0 {
 		return 0
 	}
 
-	var res uint32 = 1
+	var res bitmask = 1
 	for i := 0; i < n-1; i++ {
 		res <<= 1
 	}
-	if isStatic {
-		res |= 1 << max
-	}
 
 	return res
 }
 
-func isStatic(b uint32, max int) bool {
-	return b&(1<<max) != 0
-}
-
-func countZeros(b uint32, mask uint32) int {
+// Count zero bits of b among bits set in mask.
+// Zeros above the highest set bit of b are counted too, so row missing value size isn't taken for full
+func countZeros(b bitmask, mask bitmask) int {
 	var res int
-	b &= mask
-	for b != 0 {
+	for mask != 0 {
 		if b&1 != 1 {
 			res++
 		}
 		b >>= 1
+		mask >>= 1
 	}
 
 	return res
@@ -0,0 +1,120 @@
+package sudoku
+
+import "math/rand"
+
+// Parameters of tabu search
+type TabuConfig struct {
+	Tenure          int     // iterations during which cell can't get back its previous value
+	Iterations      int     // budget of iterations
+	Diversification float64 // weight of penalty for frequently made assignments, 0 to disable
+	Seed            int64
+	Progress        func(iter, h, best int) // called before every iteration if not nil
+}
+
+var DefaultTabu = TabuConfig{
+	Tenure:          10,
+	Iterations:      20000,
+	Diversification: 0.5,
+	Seed:            SEED,
+}
+
+// Statistics of tabu search
+type TabuStats struct {
+	Iterations  int
+	Aspirations int // tabu moves made because they improved the best state
+	Best        int // heuristic of the best state
+}
+
+// Tabu search over swaps of non-static cells inside of blocks. On every iteration the best allowed swap is made,
+// even if it makes heuristic worse. Move is tabu if it returns value to cell it was recently moved from,
+// unless it gives state better than the best found (aspiration).
+// Non-improving moves are penalized by how often they were made (long-term frequency memory).
+// s is left unchanged. Returns the best state found, it is solution if stats.Best is 0
+func (s *Sudoku) TabuSearch(cfg TabuConfig) (*Sudoku, TabuStats) {
+	r := rand.New(rand.NewSource(cfg.Seed))
+	s = s.copy()
+	s.initField()
+
+	h := s.heuristic()
+	best := s.copy()
+	stats := TabuStats{Best: h}
+	blocks := s.swappableBlocks()
+	if len(blocks) == 0 {
+		return best, stats
+	}
+
+	// Indexed by cell*size + value-1
+	tabuUntil := make([]int, len(s.field)*s.size)
+	frequency := make([]int, len(s.field)*s.size)
+	key := func(cell int, v bitmask) int {
+		return cell*s.size + getIntFromBinary(v, s.size) - 1
+	}
+
+	for iter := 1; iter <= cfg.Iterations && h != 0; iter++ {
+		if cfg.Progress != nil {
+			cfg.Progress(iter, h, stats.Best)
+		}
+
+		bestA, bestB, bestH := -1, -1, 0
+		var bestScore float64
+		ties := 0
+		aspiration := false
+		for _, cells := range blocks {
+			for m := 0; m < len(cells)-1; m++ {
+				for n := m + 1; n < len(cells); n++ {
+					a, b := cells[m], cells[n]
+					// Keys of assignments made by move
+					ka, kb := key(a, s.field[b]), key(b, s.field[a])
+
+					h1 := h + s.swapDelta(a, b)
+
+					isTabu := tabuUntil[ka] > iter || tabuUntil[kb] > iter
+					aspirated := isTabu && h1 < stats.Best
+					if isTabu && !aspirated {
+						continue
+					}
+
+					score := float64(h1)
+					if h1 >= h {
+						score += cfg.Diversification * float64(frequency[ka]+frequency[kb]) / float64(iter)
+					}
+					// Ties are broken randomly
+					switch {
+					case bestA == -1 || score < bestScore:
+						ties = 1
+					case score == bestScore:
+						if ties++; r.Intn(ties) != 0 {
+							continue
+						}
+					default:
+						continue
+					}
+					bestA, bestB, bestH, bestScore, aspiration = a, b, h1, score, aspirated
+				}
+			}
+		}
+		stats.Iterations++
+		if bestA == -1 {
+			// Every move is tabu
+			continue
+		}
+
+		// Previous values of cells become tabu for them
+		tabuUntil[key(bestA, s.field[bestA])] = iter + cfg.Tenure
+		tabuUntil[key(bestB, s.field[bestB])] = iter + cfg.Tenure
+		s.swapCells(bestA, bestB)
+		frequency[key(bestA, s.field[bestA])]++
+		frequency[key(bestB, s.field[bestB])]++
+		if aspiration {
+			stats.Aspirations++
+		}
+
+		h = bestH
+		if h < stats.Best {
+			stats.Best = h
+			best = s.copy()
+		}
+	}
+
+	return best, stats
+}
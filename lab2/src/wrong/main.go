@@ -12,7 +12,11 @@ func main() {
 		println("usage: ./main <path_to_csv>")
 		return
 	}
-	s := sudoku.NewSudoku(os.Args[1])
+	s, err := sudoku.NewSudoku(os.Args[1])
+	if err != nil {
+		println("can't load sudoku: " + err.Error())
+		return
+	}
 	fmt.Print("Unsolved sudoku:\n")
 	s.PrintSudoku(true)
 
@@ -0,0 +1,28 @@
+package sudoku
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrSize    = fmt.Errorf("size must be a perfect square from 1 to %d", MaxSize)
+	ErrRows    = errors.New("number of rows doesn't match size")
+	ErrColumns = errors.New("number of cells in row doesn't match size")
+	ErrValue   = errors.New("value must be a number from 0 to size")
+)
+
+// Error in sudoku CSV with position where it was found
+type ParseError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
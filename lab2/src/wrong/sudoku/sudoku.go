@@ -3,6 +3,7 @@ package sudoku
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
 	"os"
@@ -17,43 +18,76 @@ type Sudoku struct {
 	field   []uint32
 }
 
-func NewSudoku(path string) *Sudoku {
+// Max size of sudoku, which cells fit into uint32 together with static bit
+const MaxSize = 31
+
+func NewSudoku(path string) (*Sudoku, error) {
 	csvConf, err := os.Open(path)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	defer func() {
-		if err = csvConf.Close(); err != nil {
-			panic(err)
-		}
-	}()
+	defer csvConf.Close()
+
+	return ReadSudoku(csvConf)
+}
 
-	reader := csv.NewReader(csvConf)
+// Read sudoku from CSV: size in the first line, then rows of values separated by spaces, 0 for empty cell.
+// Errors are returned as ParseError with line and column where they were found
+func ReadSudoku(r io.Reader) (*Sudoku, error) {
+	reader := csv.NewReader(r)
 	reader.Comma = ' '
 	reader.TrimLeadingSpace = true
 	reader.FieldsPerRecord = -1
-	data, err := reader.ReadAll()
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, &ParseError{Line: 1, Column: 1, Err: ErrSize}
+	}
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	line, column := reader.FieldPos(0)
+	size, err := strconv.Atoi(header[0])
+	subSize := int(math.Sqrt(float64(size)))
+	if err != nil || size < 1 || size > MaxSize || subSize*subSize != size {
+		return nil, &ParseError{Line: line, Column: column, Err: ErrSize}
 	}
 
-	size, _ := strconv.Atoi(data[0][0])
-	sudoku := &Sudoku{size: size, subSize: int(math.Sqrt(float64(size))), field: make([]uint32, size*size)}
-	data = data[1:]
-	var isFilled bool
-	for i, row := range data {
+	sudoku := &Sudoku{size: size, subSize: subSize, field: make([]uint32, size*size)}
+	for i := 0; ; i++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			if i != size {
+				return nil, &ParseError{Line: line + 1, Column: 1, Err: ErrRows}
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, column = reader.FieldPos(0)
+		// Skip trailing spaces
+		for len(row) > 1 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		if i == size {
+			return nil, &ParseError{Line: line, Column: column, Err: ErrRows}
+		}
+		if len(row) != size {
+			return nil, &ParseError{Line: line, Column: column, Err: ErrColumns}
+		}
+
 		for j, el := range row {
-			val, _ := strconv.Atoi(el)
-			if val != 0 {
-				isFilled = true
-			} else {
-				isFilled = false
+			val, err := strconv.Atoi(el)
+			if err != nil || val < 0 || val > size {
+				line, column = reader.FieldPos(j)
+				return nil, &ParseError{Line: line, Column: column, Err: fmt.Errorf("%w, got %q", ErrValue, el)}
 			}
-			sudoku.field[i*size+j] = getBinaryFromInt(val, isFilled, size)
+			sudoku.field[i*size+j] = getBinaryFromInt(val, val != 0, size)
 		}
 	}
 
-	return sudoku
+	return sudoku, nil
 }
 
 func (s *Sudoku) Solve() *Sudoku {
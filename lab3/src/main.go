@@ -1,23 +1,67 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
 	"os"
+	"strconv"
 	"sudoku/sudoku"
 	"time"
 )
 
 func main() {
 	if len(os.Args) < 2 {
-		println("usage: ./main <path_to_csv>")
+		println("usage: ./main <path_to_csv> [csp|dlx|sat]")
+		println("       ./main validate <path_to_csv>")
+		println("       ./main solutions <path_to_csv> [limit]")
+		println("       ./main generate <size> <easy|medium|hard> [seed]")
+		println("       ./main logic <path_to_csv>")
+		println("       ./main dimacs <path_to_csv>")
+		return
+	}
+	switch os.Args[1] {
+	case "validate":
+		validate(os.Args[2:])
+		return
+	case "solutions":
+		solutions(os.Args[2:])
+		return
+	case "generate":
+		generate(os.Args[2:])
+		return
+	case "logic":
+		logic(os.Args[2:])
+		return
+	case "dimacs":
+		dimacs(os.Args[2:])
+		return
+	}
+	solver := "csp"
+	if len(os.Args) > 2 {
+		solver = os.Args[2]
+	}
+	solvers := map[string]func(*sudoku.Sudoku) *sudoku.Sudoku{
+		"csp": (*sudoku.Sudoku).Solve,
+		"dlx": (*sudoku.Sudoku).SolveDLX,
+		"sat": (*sudoku.Sudoku).SolveSAT,
+	}
+	solve, ok := solvers[solver]
+	if !ok {
+		println("unknown solver: " + solver)
+		return
+	}
+
+	s, err := sudoku.NewSudoku(os.Args[1])
+	if err != nil {
+		println("can't load sudoku: " + err.Error())
 		return
 	}
-	s := sudoku.NewSudoku(os.Args[1])
 	fmt.Print("Unsolved sudoku:\n")
 	s.PrintSudoku(true)
 
 	start := time.Now()
-	solution := s.Solve()
+	solution := solve(s)
 	finish := time.Since(start)
 
 	fmt.Println("Time elapsed: ", finish)
@@ -28,3 +72,152 @@ func main() {
 		fmt.Println("Can't solve")
 	}
 }
+
+func validate(args []string) {
+	if len(args) < 1 {
+		println("usage: ./main validate <path_to_csv>")
+		return
+	}
+	s, err := sudoku.NewSudoku(args[0])
+	if err != nil {
+		println("can't load sudoku: " + err.Error())
+		os.Exit(1)
+	}
+
+	diagnostics := s.Validate()
+	if len(diagnostics) == 0 {
+		fmt.Println("Sudoku is valid")
+		return
+	}
+	for _, d := range diagnostics {
+		fmt.Println(d)
+	}
+	os.Exit(1)
+}
+
+func solutions(args []string) {
+	if len(args) < 1 {
+		println("usage: ./main solutions <path_to_csv> [limit]")
+		return
+	}
+	limit := 0
+	if len(args) > 1 {
+		var err error
+		if limit, err = strconv.Atoi(args[1]); err != nil || limit < 0 {
+			println("limit must be a non-negative number")
+			return
+		}
+	}
+	s, err := sudoku.NewSudoku(args[0])
+	if err != nil {
+		println("can't load sudoku: " + err.Error())
+		return
+	}
+
+	start := time.Now()
+	count := 0
+	for solution := range s.Solutions(context.Background(), limit) {
+		count++
+		fmt.Printf("Solution %d:\n", count)
+		solution.PrintSudoku(false)
+	}
+
+	fmt.Println("Time elapsed: ", time.Since(start))
+	fmt.Println("Solutions found: ", count)
+	if count == 1 && (limit == 0 || limit > 1) {
+		fmt.Println("Solution is unique")
+	}
+}
+
+// Generated sudoku is written to stdout, so it can be redirected to file
+func generate(args []string) {
+	if len(args) < 2 {
+		println("usage: ./main generate <size> <easy|medium|hard> [seed]")
+		return
+	}
+	size, err := strconv.Atoi(args[0])
+	if err != nil {
+		println("size must be a number")
+		return
+	}
+	difficulty, err := sudoku.ParseDifficulty(args[1])
+	if err != nil {
+		println(err.Error())
+		return
+	}
+	seed := time.Now().UnixNano()
+	if len(args) > 2 {
+		if seed, err = strconv.ParseInt(args[2], 10, 64); err != nil {
+			println("seed must be a number")
+			return
+		}
+	}
+
+	start := time.Now()
+	s, stats, err := sudoku.Generate(size, difficulty, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		println("can't generate sudoku: " + err.Error())
+		return
+	}
+	if err = s.WriteCSV(os.Stdout); err != nil {
+		println("can't write sudoku: " + err.Error())
+		return
+	}
+
+	// Stats go to stderr, so stdout can be redirected to CSV file
+	fmt.Fprintf(os.Stderr, "Seed: %d, difficulty: %s, opened: %d, filled by propagation: %d, time elapsed: %s\n",
+		seed, stats.Difficulty(size), stats.Opened, stats.Fills, time.Since(start))
+}
+
+// Solve sudoku by logical techniques with explanation, then by search with logic as propagation
+func logic(args []string) {
+	if len(args) < 1 {
+		println("usage: ./main logic <path_to_csv>")
+		return
+	}
+	s, err := sudoku.NewSudoku(args[0])
+	if err != nil {
+		println("can't load sudoku: " + err.Error())
+		return
+	}
+	fmt.Print("Unsolved sudoku:\n")
+	s.PrintSudoku(true)
+
+	start := time.Now()
+	deductions, ok := s.Deduce()
+	for i, d := range deductions {
+		fmt.Printf("%d. %s\n", i+1, d.String(s.Size()))
+	}
+	if !ok {
+		fmt.Println("Contradiction found, can't solve")
+		return
+	}
+	fmt.Print("After deductions:\n")
+	s.PrintSudoku(false)
+
+	s.SetLogic(true)
+	solution := s.Solve()
+	fmt.Println("Time elapsed: ", time.Since(start))
+	if solution != nil {
+		fmt.Print("Solved sudoku:\n")
+		solution.PrintSudoku(false)
+	} else {
+		fmt.Println("Can't solve")
+	}
+}
+
+// CNF is written to stdout, so it can be passed to external SAT solver
+func dimacs(args []string) {
+	if len(args) < 1 {
+		println("usage: ./main dimacs <path_to_csv>")
+		return
+	}
+	s, err := sudoku.NewSudoku(args[0])
+	if err != nil {
+		println("can't load sudoku: " + err.Error())
+		return
+	}
+	if err = s.WriteDIMACS(os.Stdout); err != nil {
+		println("can't write CNF: " + err.Error())
+	}
+}
@@ -0,0 +1,362 @@
+package sat
+
+import "sort"
+
+// Statistics of solving
+type Stats struct {
+	Decisions    int
+	Propagations int
+	Conflicts    int
+	Restarts     int
+	Learnt       int // number of learnt clauses
+}
+
+// Literal of solver: 2*variable for positive literal, 2*variable+1 for negative, variables start from 0
+type lit int
+
+func (l lit) neg() lit {
+	return l ^ 1
+}
+
+func (l lit) v() int {
+	return int(l >> 1)
+}
+
+func toLit(l Lit) lit {
+	if l < 0 {
+		return lit(2*(-l-1) + 1)
+	}
+	return lit(2 * (l - 1))
+}
+
+const (
+	unassigned int8 = 0
+	isTrue     int8 = 1
+	isFalse    int8 = -1
+
+	restartBase = 100 // conflicts between restarts are restartBase times Luby sequence
+	decay       = 0.95
+)
+
+type clause struct {
+	lits []lit // first two literals are watched
+}
+
+type solver struct {
+	assigns  []int8
+	level    []int
+	reason   []*clause
+	trail    []lit
+	trailLim []int // trail length at start of every decision level
+	qhead    int   // next trail literal to propagate
+	watches  [][]*clause
+	activity []float64
+	varInc   float64
+	order    *varHeap
+	phase    []bool // last value of variable, used on decision
+	seen     []bool
+	stats    Stats
+}
+
+// Solve formula with conflict driven clause learning. Model is indexed by variable, model[0] is unused.
+// Returns false if formula is unsatisfiable
+func Solve(f *CNF) ([]bool, Stats, bool) {
+	s := &solver{
+		assigns:  make([]int8, f.Vars),
+		level:    make([]int, f.Vars),
+		reason:   make([]*clause, f.Vars),
+		watches:  make([][]*clause, 2*f.Vars),
+		activity: make([]float64, f.Vars),
+		varInc:   1,
+		phase:    make([]bool, f.Vars),
+		seen:     make([]bool, f.Vars),
+	}
+	s.order = newVarHeap(s.activity)
+
+	for _, c := range f.Clauses {
+		if !s.addClause(c) {
+			return nil, s.stats, false
+		}
+	}
+	if !s.search() {
+		return nil, s.stats, false
+	}
+
+	model := make([]bool, f.Vars+1)
+	for v, a := range s.assigns {
+		model[v+1] = a == isTrue
+	}
+
+	return model, s.stats, true
+}
+
+func (s *solver) value(l lit) int8 {
+	if l&1 == 1 {
+		return -s.assigns[l.v()]
+	}
+	return s.assigns[l.v()]
+}
+
+func (s *solver) decisionLevel() int {
+	return len(s.trailLim)
+}
+
+// Add clause of formula before search, returns false if formula is trivially unsatisfiable
+func (s *solver) addClause(c []Lit) bool {
+	lits := make([]lit, 0, len(c))
+	for _, l := range c {
+		lits = append(lits, toLit(l))
+	}
+	sort.Slice(lits, func(i, j int) bool { return lits[i] < lits[j] })
+
+	// Remove duplicates, skip tautologies
+	res := lits[:0]
+	for i, l := range lits {
+		if i > 0 && l == lits[i-1] {
+			continue
+		}
+		if i > 0 && l == lits[i-1].neg() {
+			return true
+		}
+		res = append(res, l)
+	}
+
+	switch len(res) {
+	case 0:
+		return false
+	case 1:
+		switch s.value(res[0]) {
+		case isFalse:
+			return false
+		case unassigned:
+			s.enqueue(res[0], nil)
+		}
+	default:
+		s.attach(&clause{lits: res})
+	}
+
+	return true
+}
+
+func (s *solver) attach(c *clause) {
+	s.watches[c.lits[0]] = append(s.watches[c.lits[0]], c)
+	s.watches[c.lits[1]] = append(s.watches[c.lits[1]], c)
+}
+
+func (s *solver) enqueue(l lit, from *clause) {
+	v := l.v()
+	s.assigns[v] = isTrue
+	if l&1 == 1 {
+		s.assigns[v] = isFalse
+	}
+	s.level[v] = s.decisionLevel()
+	s.reason[v] = from
+	s.trail = append(s.trail, l)
+}
+
+// Unit propagation with two watched literals, returns conflicting clause or nil
+func (s *solver) propagate() *clause {
+	for s.qhead < len(s.trail) {
+		falseLit := s.trail[s.qhead].neg()
+		s.qhead++
+		s.stats.Propagations++
+
+		ws := s.watches[falseLit]
+		i, j := 0, 0
+		for i < len(ws) {
+			c := ws[i]
+			i++
+			// Keep false literal second
+			if c.lits[0] == falseLit {
+				c.lits[0], c.lits[1] = c.lits[1], c.lits[0]
+			}
+			if s.value(c.lits[0]) == isTrue {
+				ws[j] = c
+				j++
+				continue
+			}
+
+			// Look for new literal to watch
+			moved := false
+			for k := 2; k < len(c.lits); k++ {
+				if s.value(c.lits[k]) != isFalse {
+					c.lits[1], c.lits[k] = c.lits[k], c.lits[1]
+					s.watches[c.lits[1]] = append(s.watches[c.lits[1]], c)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+
+			ws[j] = c
+			j++
+			if s.value(c.lits[0]) == isFalse {
+				j += copy(ws[j:], ws[i:])
+				s.watches[falseLit] = ws[:j]
+				s.qhead = len(s.trail)
+				return c
+			}
+			s.enqueue(c.lits[0], c)
+		}
+		s.watches[falseLit] = ws[:j]
+	}
+
+	return nil
+}
+
+// Learn first UIP clause from conflict, returns it with asserting literal first and level to backtrack to
+func (s *solver) analyze(confl *clause) ([]lit, int) {
+	learnt := []lit{0}
+	pathCount := 0
+	var p lit = -1
+	idx := len(s.trail) - 1
+
+	for {
+		start := 0
+		if p != -1 {
+			// First literal of reason is p itself
+			start = 1
+		}
+		for _, q := range confl.lits[start:] {
+			v := q.v()
+			if s.seen[v] || s.level[v] == 0 {
+				continue
+			}
+			s.seen[v] = true
+			s.bump(v)
+			if s.level[v] == s.decisionLevel() {
+				pathCount++
+			} else {
+				learnt = append(learnt, q)
+			}
+		}
+
+		for !s.seen[s.trail[idx].v()] {
+			idx--
+		}
+		p = s.trail[idx]
+		idx--
+		confl = s.reason[p.v()]
+		s.seen[p.v()] = false
+		pathCount--
+		if pathCount == 0 {
+			break
+		}
+	}
+	learnt[0] = p.neg()
+
+	// Second watched literal is from the highest level except current
+	btLevel := 0
+	for i := 1; i < len(learnt); i++ {
+		s.seen[learnt[i].v()] = false
+		if l := s.level[learnt[i].v()]; l > btLevel {
+			btLevel = l
+			learnt[1], learnt[i] = learnt[i], learnt[1]
+		}
+	}
+
+	return learnt, btLevel
+}
+
+func (s *solver) bump(v int) {
+	s.activity[v] += s.varInc
+	if s.activity[v] > 1e100 {
+		for i := range s.activity {
+			s.activity[i] *= 1e-100
+		}
+		s.varInc *= 1e-100
+	}
+	s.order.increased(v)
+}
+
+func (s *solver) backtrack(level int) {
+	if s.decisionLevel() <= level {
+		return
+	}
+	for i := len(s.trail) - 1; i >= s.trailLim[level]; i-- {
+		v := s.trail[i].v()
+		s.phase[v] = s.assigns[v] == isTrue
+		s.assigns[v] = unassigned
+		s.reason[v] = nil
+		s.order.push(v)
+	}
+	s.trail = s.trail[:s.trailLim[level]]
+	s.trailLim = s.trailLim[:level]
+	s.qhead = len(s.trail)
+}
+
+// Unassigned variable with the highest activity, -1 if all are assigned
+func (s *solver) pickBranch() int {
+	for !s.order.empty() {
+		if v := s.order.pop(); s.assigns[v] == unassigned {
+			return v
+		}
+	}
+
+	return -1
+}
+
+func (s *solver) search() bool {
+	restartLimit := restartBase * luby(0)
+	conflicts := 0
+
+	for {
+		if confl := s.propagate(); confl != nil {
+			s.stats.Conflicts++
+			conflicts++
+			if s.decisionLevel() == 0 {
+				return false
+			}
+
+			learnt, btLevel := s.analyze(confl)
+			s.backtrack(btLevel)
+			if len(learnt) == 1 {
+				s.enqueue(learnt[0], nil)
+			} else {
+				c := &clause{lits: learnt}
+				s.attach(c)
+				s.enqueue(learnt[0], c)
+				s.stats.Learnt++
+			}
+			s.varInc /= decay
+
+			if conflicts >= restartLimit {
+				s.stats.Restarts++
+				conflicts = 0
+				restartLimit = restartBase * luby(s.stats.Restarts)
+				s.backtrack(0)
+			}
+			continue
+		}
+
+		v := s.pickBranch()
+		if v == -1 {
+			return true
+		}
+		s.stats.Decisions++
+		s.trailLim = append(s.trailLim, len(s.trail))
+		l := lit(2*v + 1)
+		if s.phase[v] {
+			l = lit(2 * v)
+		}
+		s.enqueue(l, nil)
+	}
+}
+
+// i-th element of Luby sequence 1, 1, 2, 1, 1, 2, 4, ...
+func luby(i int) int {
+	size, seq := 1, 0
+	for size < i+1 {
+		seq++
+		size = 2*size + 1
+	}
+	for size-1 != i {
+		size = (size - 1) / 2
+		seq--
+		i %= size
+	}
+
+	return 1 << seq
+}
@@ -0,0 +1,46 @@
+package sat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Literal in DIMACS notation: variable number, negative if negated. Variables start from 1
+type Lit int
+
+func (l Lit) Var() int {
+	if l < 0 {
+		return int(-l)
+	}
+	return int(l)
+}
+
+// Formula in conjunctive normal form
+type CNF struct {
+	Vars    int
+	Clauses [][]Lit
+}
+
+func (f *CNF) AddClause(lits ...Lit) {
+	for _, l := range lits {
+		if l.Var() > f.Vars {
+			f.Vars = l.Var()
+		}
+	}
+	f.Clauses = append(f.Clauses, lits)
+}
+
+// Write formula in DIMACS CNF format
+func (f *CNF) WriteDIMACS(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p cnf %d %d\n", f.Vars, len(f.Clauses))
+	for _, c := range f.Clauses {
+		for _, l := range c {
+			fmt.Fprintf(bw, "%d ", l)
+		}
+		fmt.Fprintln(bw, 0)
+	}
+
+	return bw.Flush()
+}
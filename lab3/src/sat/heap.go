@@ -0,0 +1,92 @@
+package sat
+
+// Max heap of variables by activity, positions are kept to update variables in place
+type varHeap struct {
+	activity []float64
+	heap     []int
+	pos      []int // position of variable in heap, -1 if absent
+}
+
+func newVarHeap(activity []float64) *varHeap {
+	h := &varHeap{activity: activity, pos: make([]int, len(activity))}
+	for v := range activity {
+		h.pos[v] = len(h.heap)
+		h.heap = append(h.heap, v)
+	}
+
+	return h
+}
+
+func (h *varHeap) less(i, j int) bool {
+	return h.activity[h.heap[i]] > h.activity[h.heap[j]]
+}
+
+func (h *varHeap) swap(i, j int) {
+	h.heap[i], h.heap[j] = h.heap[j], h.heap[i]
+	h.pos[h.heap[i]] = i
+	h.pos[h.heap[j]] = j
+}
+
+func (h *varHeap) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(i, parent) {
+			break
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
+
+func (h *varHeap) down(i int) {
+	for {
+		best := i
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child < len(h.heap) && h.less(child, best) {
+				best = child
+			}
+		}
+		if best == i {
+			return
+		}
+		h.swap(i, best)
+		i = best
+	}
+}
+
+func (h *varHeap) empty() bool {
+	return len(h.heap) == 0
+}
+
+func (h *varHeap) contains(v int) bool {
+	return h.pos[v] != -1
+}
+
+func (h *varHeap) push(v int) {
+	if h.contains(v) {
+		return
+	}
+	h.pos[v] = len(h.heap)
+	h.heap = append(h.heap, v)
+	h.up(h.pos[v])
+}
+
+func (h *varHeap) pop() int {
+	v := h.heap[0]
+	last := len(h.heap) - 1
+	h.swap(0, last)
+	h.heap = h.heap[:last]
+	h.pos[v] = -1
+	if last > 0 {
+		h.down(0)
+	}
+
+	return v
+}
+
+// Restore heap order after activity of v increased
+func (h *varHeap) increased(v int) {
+	if h.contains(v) {
+		h.up(h.pos[v])
+	}
+}
@@ -1,7 +1,7 @@
 package sudoku
 
-func (s *Sudoku) verticalConstraint(idx int) uint32 {
-	var res uint32
+func (s *Sudoku) verticalConstraint(idx int) bitmask {
+	var res bitmask
 
 	j := idx % s.size
 
@@ -12,8 +12,8 @@ func (s *Sudoku) verticalConstraint(idx int) uint32 {
 	return res
 }
 
-func (s *Sudoku) horizontalConstraint(idx int) uint32 {
-	var res uint32
+func (s *Sudoku) horizontalConstraint(idx int) bitmask {
+	var res bitmask
 
 	i := idx / s.size
 
@@ -24,27 +24,36 @@ func (s *Sudoku) horizontalConstraint(idx int) uint32 {
 	return res
 }
 
-func (s *Sudoku) blockConstraint(idx int) uint32 {
-	var res uint32
+func (s *Sudoku) blockConstraint(idx int) bitmask {
+	var res bitmask
 
-	// Calculate block indexes
-	i := idx / (s.subSize * s.size)
-	j := (idx % s.size) / s.subSize
-
-	for k := 0; k < s.subSize; k++ {
-		for l := 0; l < s.subSize; l++ {
-			res |= s.field[i*s.subSize*s.size+k*s.size+j*s.subSize+l]
+	start := s.blockStart(s.block(idx))
+	for k := 0; k < s.boxHeight; k++ {
+		for l := 0; l < s.boxWidth; l++ {
+			res |= s.field[start+k*s.size+l]
 		}
 	}
 
 	return res
 }
 
+// Index of block containing cell, blocks are numbered row by row
+func (s *Sudoku) block(idx int) int {
+	i, j := idx/s.size, idx%s.size
+	return (i/s.boxHeight)*(s.size/s.boxWidth) + j/s.boxWidth
+}
+
+// Index of top left cell of block
+func (s *Sudoku) blockStart(block int) int {
+	perRow := s.size / s.boxWidth
+	return (block/perRow)*s.boxHeight*s.size + (block%perRow)*s.boxWidth
+}
+
 func (s *Sudoku) heuristic() int {
 	var res int
 	// horizontal
 	for i := 0; i < s.size; i++ {
-		var heuristic uint32
+		var heuristic bitmask
 		for j := 0; j < s.size; j++ {
 			heuristic |= s.field[i*s.size+j]
 		}
@@ -53,7 +62,7 @@ func (s *Sudoku) heuristic() int {
 
 	// vertical
 	for j := 0; j < s.size; j++ {
-		var heuristic uint32
+		var heuristic bitmask
 		for i := 0; i < s.size; i++ {
 			heuristic |= s.field[i*s.size+j]
 		}
@@ -61,16 +70,15 @@ func (s *Sudoku) heuristic() int {
 	}
 
 	// block
-	for i := 0; i < s.subSize; i++ {
-		for j := 0; j < s.subSize; j++ {
-			var heuristic uint32
-			for k := 0; k < s.subSize; k++ {
-				for l := 0; l < s.subSize; l++ {
-					heuristic |= s.field[i*s.subSize*s.size+k*s.size+j*s.subSize+l]
-				}
+	for b := 0; b < s.size; b++ {
+		var heuristic bitmask
+		start := s.blockStart(b)
+		for k := 0; k < s.boxHeight; k++ {
+			for l := 0; l < s.boxWidth; l++ {
+				heuristic |= s.field[start+k*s.size+l]
 			}
-			res += countZeros(heuristic, s.size)
 		}
+		res += countZeros(heuristic, s.size)
 	}
 
 	return res
@@ -0,0 +1,276 @@
+package sudoku
+
+// Exact cover matrix as toroidal doubly linked lists, nodes are stored in slices.
+// Node 0 is root, nodes 1..columns are column headers
+type dlx struct {
+	left, right, up, down []int
+	col                   []int // column header of node
+	row                   []int // matrix row of node
+	count                 []int // number of nodes in column, indexed by header
+	solution              []int // rows of partial solution, the first depth of them are selected
+	depth                 int
+	hidden                []int // first nodes of rows removed by reduce, restored on backtrack
+}
+
+func newDLX(columns, rows int) *dlx {
+	d := &dlx{count: make([]int, columns+1), solution: make([]int, rows)}
+	for i := 0; i <= columns; i++ {
+		d.left = append(d.left, i-1)
+		d.right = append(d.right, i+1)
+		d.up = append(d.up, i)
+		d.down = append(d.down, i)
+		d.col = append(d.col, i)
+		d.row = append(d.row, -1)
+	}
+	d.left[0] = columns
+	d.right[columns] = 0
+
+	return d
+}
+
+// Add matrix row with ones in columns (1-based)
+func (d *dlx) addRow(row int, columns []int) {
+	first := len(d.col)
+	for i, c := range columns {
+		n := len(d.col)
+		d.col = append(d.col, c)
+		d.row = append(d.row, row)
+		d.up = append(d.up, d.up[c])
+		d.down = append(d.down, c)
+		d.down[d.up[c]] = n
+		d.up[c] = n
+		d.count[c]++
+
+		if i == 0 {
+			d.left = append(d.left, n)
+			d.right = append(d.right, n)
+		} else {
+			d.left = append(d.left, n-1)
+			d.right = append(d.right, first)
+			d.right[n-1] = n
+			d.left[first] = n
+		}
+	}
+}
+
+func (d *dlx) cover(c int) {
+	d.right[d.left[c]] = d.right[c]
+	d.left[d.right[c]] = d.left[c]
+	for i := d.down[c]; i != c; i = d.down[i] {
+		for j := d.right[i]; j != i; j = d.right[j] {
+			d.down[d.up[j]] = d.down[j]
+			d.up[d.down[j]] = d.up[j]
+			d.count[d.col[j]]--
+		}
+	}
+}
+
+func (d *dlx) uncover(c int) {
+	for i := d.up[c]; i != c; i = d.up[i] {
+		for j := d.left[i]; j != i; j = d.left[j] {
+			d.count[d.col[j]]++
+			d.down[d.up[j]] = j
+			d.up[d.down[j]] = j
+		}
+	}
+	d.right[d.left[c]] = c
+	d.left[d.right[c]] = c
+}
+
+// Remove matrix row with node n from columns, which are not covered
+func (d *dlx) hide(n int) {
+	for j := n; ; {
+		d.down[d.up[j]] = d.down[j]
+		d.up[d.down[j]] = d.up[j]
+		d.count[d.col[j]]--
+		if j = d.right[j]; j == n {
+			break
+		}
+	}
+	d.hidden = append(d.hidden, n)
+}
+
+// Restore rows hidden after the first mark of them
+func (d *dlx) restore(mark int) {
+	for len(d.hidden) > mark {
+		n := d.hidden[len(d.hidden)-1]
+		d.hidden = d.hidden[:len(d.hidden)-1]
+		for j := d.left[n]; ; j = d.left[j] {
+			d.count[d.col[j]]++
+			d.down[d.up[j]] = j
+			d.up[d.down[j]] = j
+			if j == n {
+				break
+			}
+		}
+	}
+}
+
+// Check if row of node n has node in column c
+func (d *dlx) rowHas(n, c int) bool {
+	for j := n; ; {
+		if d.col[j] == c {
+			return true
+		}
+		if j = d.right[j]; j == n {
+			return false
+		}
+	}
+}
+
+// Check if every row of column a has node in column b
+func (d *dlx) covers(a, b int) bool {
+	for i := d.down[a]; i != a; i = d.down[i] {
+		if !d.rowHas(i, b) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// If every row of column a is in column b, column a will be covered by one of them and cover b too,
+// so other rows of b can't be selected. Rows are hidden until nothing changes.
+// Returns false if some column has no rows left
+func (d *dlx) reduce() bool {
+	for changed := true; changed; {
+		changed = false
+		for a := d.right[0]; a != 0; a = d.right[a] {
+			if d.count[a] == 0 {
+				return false
+			}
+			first := d.down[a]
+			for j := d.right[first]; j != first; j = d.right[j] {
+				b := d.col[j]
+				if d.count[b] <= d.count[a] || !d.covers(a, b) {
+					continue
+				}
+				for i := d.down[b]; i != b; {
+					next := d.down[i]
+					if !d.rowHas(i, a) {
+						d.hide(i)
+					}
+					i = next
+				}
+				changed = true
+			}
+		}
+	}
+
+	return true
+}
+
+// Select matrix row into solution, covering all its columns
+func (d *dlx) selectRow(n int) {
+	d.solution[d.depth] = d.row[n]
+	d.depth++
+	for j := n; ; {
+		d.cover(d.col[j])
+		if j = d.right[j]; j == n {
+			break
+		}
+	}
+}
+
+// Get column with the fewest rows
+func (d *dlx) chooseColumn() int {
+	c := d.right[0]
+	for j := d.right[c]; j != 0 && d.count[c] > 1; j = d.right[j] {
+		if d.count[j] < d.count[c] {
+			c = j
+		}
+	}
+
+	return c
+}
+
+// Algorithm X: reduce matrix, cover column with the fewest rows and try each of them
+func (d *dlx) search() bool {
+	if d.right[0] == 0 {
+		return true
+	}
+
+	mark := len(d.hidden)
+	defer d.restore(mark)
+	if !d.reduce() {
+		return false
+	}
+	c := d.chooseColumn()
+	if d.count[c] == 0 {
+		return false
+	}
+
+	d.cover(c)
+	for i := d.down[c]; i != c; i = d.down[i] {
+		d.solution[d.depth] = d.row[i]
+		d.depth++
+		for j := d.right[i]; j != i; j = d.right[j] {
+			d.cover(d.col[j])
+		}
+
+		if d.search() {
+			return true
+		}
+
+		d.depth--
+		for j := d.left[i]; j != i; j = d.left[j] {
+			d.uncover(d.col[j])
+		}
+	}
+	d.uncover(c)
+
+	return false
+}
+
+// Solve sudoku as exact cover problem with Dancing Links.
+// Matrix row is cell with value, columns are constraints:
+// cell is filled, row has value, column has value, block has value
+func (s *Sudoku) SolveDLX() *Sudoku {
+	n := s.size
+	d := newDLX(4*n*n, n*n)
+	// First node of every matrix row to select givens
+	first := make([]int, n*n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			b := s.block(i*n + j)
+			for v := 0; v < n; v++ {
+				r := (i*n+j)*n + v
+				first[r] = len(d.col)
+				d.addRow(r, []int{
+					1 + i*n + j,
+					1 + n*n + i*n + v,
+					1 + 2*n*n + j*n + v,
+					1 + 3*n*n + b*n + v,
+				})
+			}
+		}
+	}
+
+	for c, v := range s.field {
+		if v == 0 {
+			continue
+		}
+		r := c*n + getIntFromBinary(v, n) - 1
+		// Column already covered by other given, so sudoku is contradictory
+		for j := first[r]; ; {
+			if c := d.col[j]; d.right[d.left[c]] != c {
+				return nil
+			}
+			if j = d.right[j]; j == first[r] {
+				break
+			}
+		}
+		d.selectRow(first[r])
+	}
+
+	if !d.search() {
+		return nil
+	}
+
+	res := &Sudoku{size: s.size, boxHeight: s.boxHeight, boxWidth: s.boxWidth, field: make([]bitmask, len(s.field))}
+	for _, r := range d.solution[:d.depth] {
+		res.field[r/n] = getBinaryFromInt(r%n+1, n)
+	}
+
+	return res
+}
@@ -0,0 +1,130 @@
+package sudoku
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+type Difficulty int
+
+const (
+	Easy Difficulty = iota
+	Medium
+	Hard
+)
+
+// Number of full grids tried before generator gives up reaching difficulty
+const generateAttempts = 10
+
+func (d Difficulty) String() string {
+	switch d {
+	case Easy:
+		return "easy"
+	case Medium:
+		return "medium"
+	case Hard:
+		return "hard"
+	}
+
+	return "unknown"
+}
+
+func ParseDifficulty(s string) (Difficulty, error) {
+	for _, d := range []Difficulty{Easy, Medium, Hard} {
+		if d.String() == s {
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown difficulty %q", s)
+}
+
+// Difficulty of sudoku of size, which was solved with stats.
+// Easy sudoku is solved by propagation alone, without branching,
+// medium one needs at most one opened state per row
+func (st Stats) Difficulty(size int) Difficulty {
+	switch {
+	case st.Opened <= 1:
+		return Easy
+	case st.Opened <= size:
+		return Medium
+	}
+
+	return Hard
+}
+
+// Generate sudoku with unique solution and given difficulty.
+// Givens are removed from random full grid while solution stays unique and sudoku is not harder than needed.
+// If difficulty is not reached, the closest sudoku is returned.
+// Stats are collected while proving uniqueness of the result
+func Generate(size int, difficulty Difficulty, r *rand.Rand) (*Sudoku, Stats, error) {
+	box := int(math.Sqrt(float64(size)))
+	if size < 1 || size > MaxSize || box*box != size {
+		return nil, Stats{}, ErrSize
+	}
+
+	var best *Sudoku
+	var bestStats Stats
+	for attempt := 0; attempt < generateAttempts; attempt++ {
+		s, stats := randomGrid(size, box, box, r).removeGivens(difficulty, r)
+		if best == nil || stats.Difficulty(size) > bestStats.Difficulty(size) {
+			best, bestStats = s, stats
+		}
+		if stats.Difficulty(size) == difficulty {
+			break
+		}
+	}
+
+	return best, bestStats, nil
+}
+
+// Random full grid: base pattern with shuffled values, bands, stacks, rows and columns inside of them
+func randomGrid(size, boxHeight, boxWidth int, r *rand.Rand) *Sudoku {
+	s := &Sudoku{size: size, boxHeight: boxHeight, boxWidth: boxWidth, field: make([]bitmask, size*size)}
+
+	values := r.Perm(size)
+	rows := shuffleLines(size/boxHeight, boxHeight, r)
+	columns := shuffleLines(size/boxWidth, boxWidth, r)
+	for i, row := range rows {
+		for j, column := range columns {
+			v := (boxWidth*(row%boxHeight) + row/boxHeight + column) % size
+			s.field[i*size+j] = getBinaryFromInt(values[v]+1, size)
+		}
+	}
+
+	return s
+}
+
+// Indexes of lines in random order, lines of one band stay together
+func shuffleLines(bands, lines int, r *rand.Rand) []int {
+	var res []int
+	for _, band := range r.Perm(bands) {
+		for _, line := range r.Perm(lines) {
+			res = append(res, band*lines+line)
+		}
+	}
+
+	return res
+}
+
+// Remove givens in random order, while solution is unique and sudoku is not harder than difficulty
+func (s *Sudoku) removeGivens(difficulty Difficulty, r *rand.Rand) (*Sudoku, Stats) {
+	res := &Sudoku{size: s.size, boxHeight: s.boxHeight, boxWidth: s.boxWidth}
+	res.field = append(res.field, s.field...)
+
+	_, stats := res.countSolutions(2)
+	for _, i := range r.Perm(len(res.field)) {
+		v := res.field[i]
+		res.field[i] = 0
+
+		count, st := res.countSolutions(2)
+		if count != 1 || st.Difficulty(s.size) > difficulty {
+			res.field[i] = v
+			continue
+		}
+		stats = st
+	}
+
+	return res, stats
+}
@@ -0,0 +1,455 @@
+package sudoku
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+type Technique int
+
+const (
+	NakedSingle Technique = iota
+	HiddenSingle
+	NakedPair
+	NakedTriple
+	HiddenPair
+	HiddenTriple
+	PointingPair
+	BoxLineReduction
+	XWing
+)
+
+var techniqueNames = [...]string{
+	NakedSingle:      "naked single",
+	HiddenSingle:     "hidden single",
+	NakedPair:        "naked pair",
+	NakedTriple:      "naked triple",
+	HiddenPair:       "hidden pair",
+	HiddenTriple:     "hidden triple",
+	PointingPair:     "pointing pair",
+	BoxLineReduction: "box-line reduction",
+	XWing:            "X-wing",
+}
+
+func (t Technique) String() string {
+	return techniqueNames[t]
+}
+
+// Candidates removed from cell
+type Removal struct {
+	Cell   int
+	Values bitmask
+}
+
+// One step of logical solving
+type Deduction struct {
+	Technique Technique
+	Cells     []int     // cells pattern is made of
+	Values    bitmask   // candidates pattern is made of
+	Removed   []Removal // candidates removed from cells
+}
+
+func (d Deduction) String(size int) string {
+	sb := strings.Builder{}
+	sb.WriteString(d.Technique.String())
+	for _, c := range d.Cells {
+		sb.WriteString(" " + cellName(c, size))
+	}
+	sb.WriteString(" " + valuesName(d.Values, size) + ", removed:")
+	for _, r := range d.Removed {
+		sb.WriteString(" " + cellName(r.Cell, size) + valuesName(r.Values, size))
+	}
+
+	return sb.String()
+}
+
+func cellName(c, size int) string {
+	return fmt.Sprintf("r%dc%d", c/size+1, c%size+1)
+}
+
+func valuesName(values bitmask, size int) string {
+	var names []string
+	for _, v := range extractDomain(^values, size) {
+		names = append(names, fmt.Sprint(getIntFromBinary(v, size)))
+	}
+
+	return "{" + strings.Join(names, ",") + "}"
+}
+
+// Apply logical techniques to sudoku until none of them works, cells found are filled in place.
+// Returns log of deductions and false if sudoku has turned out to be unsolvable
+func (s *Sudoku) Deduce() ([]Deduction, bool) {
+	l := newLogic(s)
+	finders := []func() *Deduction{
+		l.nakedSingle,
+		l.hiddenSingle,
+		func() *Deduction { return l.nakedSubset(2, NakedPair) },
+		func() *Deduction { return l.hiddenSubset(2, HiddenPair) },
+		func() *Deduction { return l.nakedSubset(3, NakedTriple) },
+		func() *Deduction { return l.hiddenSubset(3, HiddenTriple) },
+		l.pointing,
+		l.boxLine,
+		l.xWing,
+	}
+
+	var log []Deduction
+	for l.isValid() {
+		// Simpler techniques are tried first
+		var d *Deduction
+		for _, find := range finders {
+			if d = find(); d != nil {
+				break
+			}
+		}
+		if d == nil {
+			return log, true
+		}
+
+		l.apply(d)
+		log = append(log, *d)
+	}
+
+	return log, false
+}
+
+// Candidates of cells, rows, columns and blocks of sudoku
+type logic struct {
+	s         *Sudoku
+	cand      []bitmask
+	units     [][]int // rows, then columns, then blocks
+	cellUnits [][3]int
+}
+
+func newLogic(s *Sudoku) *logic {
+	l := &logic{s: s, cellUnits: make([][3]int, len(s.field))}
+
+	l.units = make([][]int, 3*s.size)
+	for i := 0; i < s.size; i++ {
+		for j := 0; j < s.size; j++ {
+			c := i*s.size + j
+			block := s.block(c)
+			l.cellUnits[c] = [3]int{i, s.size + j, 2*s.size + block}
+			for _, u := range l.cellUnits[c] {
+				l.units[u] = append(l.units[u], c)
+			}
+		}
+	}
+
+	// Domains of search are pruned in place
+	if s.domains != nil {
+		l.cand = s.domains
+		return l
+	}
+	var mask bitmask = 1<<s.size - 1
+	l.cand = make([]bitmask, len(s.field))
+	for c, v := range s.field {
+		if v != 0 {
+			l.cand[c] = v
+		} else {
+			l.cand[c] = ^(s.horizontalConstraint(c) | s.verticalConstraint(c) | s.blockConstraint(c)) & mask
+		}
+	}
+
+	return l
+}
+
+// Remove candidates, fill cell if deduction is single
+func (l *logic) apply(d *Deduction) {
+	for _, r := range d.Removed {
+		l.cand[r.Cell] &^= r.Values
+	}
+	if d.Technique == NakedSingle || d.Technique == HiddenSingle {
+		l.s.field[d.Cells[0]] = d.Values
+	}
+}
+
+// Check that every empty cell has candidates and every value has place in every unit
+func (l *logic) isValid() bool {
+	for c, v := range l.cand {
+		if v == 0 && l.s.field[c] == 0 {
+			return false
+		}
+	}
+	var mask bitmask = 1<<l.s.size - 1
+	for _, u := range l.units {
+		var all bitmask
+		for _, c := range u {
+			all |= l.cand[c]
+		}
+		if all != mask {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Fill cell with value and remove value from candidates of peers
+func (l *logic) single(technique Technique, c int, v bitmask) *Deduction {
+	d := &Deduction{Technique: technique, Cells: []int{c}, Values: v}
+	if other := l.cand[c] &^ v; other != 0 {
+		d.Removed = append(d.Removed, Removal{Cell: c, Values: other})
+	}
+	seen := map[int]struct{}{c: {}}
+	for _, u := range l.cellUnits[c] {
+		for _, p := range l.units[u] {
+			if _, ok := seen[p]; ok {
+				continue
+			}
+			seen[p] = struct{}{}
+			if l.s.field[p] == 0 && l.cand[p]&v != 0 {
+				d.Removed = append(d.Removed, Removal{Cell: p, Values: v})
+			}
+		}
+	}
+
+	return d
+}
+
+// Empty cell with single candidate
+func (l *logic) nakedSingle() *Deduction {
+	for c, v := range l.cand {
+		if l.s.field[c] == 0 && bits.OnesCount64(v) == 1 {
+			return l.single(NakedSingle, c, v)
+		}
+	}
+
+	return nil
+}
+
+// Value which has single place in unit
+func (l *logic) hiddenSingle() *Deduction {
+	for _, u := range l.units {
+		for _, v := range l.unplaced(u) {
+			if cells := l.cellsWith(u, v); len(cells) == 1 {
+				return l.single(HiddenSingle, cells[0], v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// k empty cells of unit with k candidates in total, candidates are removed from other cells of unit
+func (l *logic) nakedSubset(k int, technique Technique) *Deduction {
+	for _, u := range l.units {
+		empty := l.empty(u)
+		for _, idx := range combinations(len(empty), k) {
+			var values bitmask
+			cells := make([]int, 0, k)
+			for _, i := range idx {
+				values |= l.cand[empty[i]]
+				cells = append(cells, empty[i])
+			}
+			if bits.OnesCount64(values) != k {
+				continue
+			}
+
+			d := &Deduction{Technique: technique, Cells: cells, Values: values}
+			for _, c := range empty {
+				if !contains(cells, c) && l.cand[c]&values != 0 {
+					d.Removed = append(d.Removed, Removal{Cell: c, Values: l.cand[c] & values})
+				}
+			}
+			if len(d.Removed) != 0 {
+				return d
+			}
+		}
+	}
+
+	return nil
+}
+
+// k values which have only k places in unit, other candidates are removed from these cells
+func (l *logic) hiddenSubset(k int, technique Technique) *Deduction {
+	for _, u := range l.units {
+		unplaced := l.unplaced(u)
+		for _, idx := range combinations(len(unplaced), k) {
+			var values bitmask
+			for _, i := range idx {
+				values |= unplaced[i]
+			}
+			cells := l.cellsWith(u, values)
+			if len(cells) != k {
+				continue
+			}
+
+			d := &Deduction{Technique: technique, Cells: cells, Values: values}
+			for _, c := range cells {
+				if other := l.cand[c] &^ values; other != 0 {
+					d.Removed = append(d.Removed, Removal{Cell: c, Values: other})
+				}
+			}
+			if len(d.Removed) != 0 {
+				return d
+			}
+		}
+	}
+
+	return nil
+}
+
+// Candidates of value in block lie in one line, value is removed from the rest of line
+func (l *logic) pointing() *Deduction {
+	for b := 2 * l.s.size; b < 3*l.s.size; b++ {
+		for _, kind := range [2]int{0, 1} {
+			if d := l.intersection(b, kind, PointingPair); d != nil {
+				return d
+			}
+		}
+	}
+
+	return nil
+}
+
+// Candidates of value in line lie in one block, value is removed from the rest of block
+func (l *logic) boxLine() *Deduction {
+	for u := 0; u < 2*l.s.size; u++ {
+		if d := l.intersection(u, 2, BoxLineReduction); d != nil {
+			return d
+		}
+	}
+
+	return nil
+}
+
+// Look for value of unit u, which candidates share other unit of kind, and remove value from that unit
+func (l *logic) intersection(u int, kind int, technique Technique) *Deduction {
+	for _, v := range l.unplaced(l.units[u]) {
+		cells := l.cellsWith(l.units[u], v)
+		if len(cells) < 2 {
+			continue
+		}
+		other := l.cellUnits[cells[0]][kind]
+		shared := true
+		for _, c := range cells[1:] {
+			if l.cellUnits[c][kind] != other {
+				shared = false
+			}
+		}
+		if !shared {
+			continue
+		}
+
+		d := &Deduction{Technique: technique, Cells: cells, Values: v}
+		for _, c := range l.cellsWith(l.units[other], v) {
+			if !contains(cells, c) {
+				d.Removed = append(d.Removed, Removal{Cell: c, Values: v})
+			}
+		}
+		if len(d.Removed) != 0 {
+			return d
+		}
+	}
+
+	return nil
+}
+
+// Value has two places in each of two rows and they are in the same columns,
+// value is removed from the rest of columns. The same for columns and rows
+func (l *logic) xWing() *Deduction {
+	size := l.s.size
+	for _, lines := range [2]int{0, size} {
+		// Kind of crossing lines in cellUnits
+		k := 1
+		if lines == size {
+			k = 0
+		}
+		for _, v := range extractDomain(0, size) {
+			for a := lines; a < lines+size; a++ {
+				first := l.cellsWith(l.units[a], v)
+				if len(first) != 2 {
+					continue
+				}
+				for b := a + 1; b < lines+size; b++ {
+					second := l.cellsWith(l.units[b], v)
+					if len(second) != 2 {
+						continue
+					}
+					c1, c2 := l.cellUnits[first[0]][k], l.cellUnits[first[1]][k]
+					if l.cellUnits[second[0]][k] != c1 || l.cellUnits[second[1]][k] != c2 {
+						continue
+					}
+
+					cells := append(append([]int{}, first...), second...)
+					d := &Deduction{Technique: XWing, Cells: cells, Values: v}
+					for _, u := range []int{c1, c2} {
+						for _, c := range l.cellsWith(l.units[u], v) {
+							if !contains(cells, c) {
+								d.Removed = append(d.Removed, Removal{Cell: c, Values: v})
+							}
+						}
+					}
+					if len(d.Removed) != 0 {
+						return d
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Empty cells of unit
+func (l *logic) empty(u []int) []int {
+	var res []int
+	for _, c := range u {
+		if l.s.field[c] == 0 {
+			res = append(res, c)
+		}
+	}
+
+	return res
+}
+
+// Values which are not placed in unit yet
+func (l *logic) unplaced(u []int) []bitmask {
+	var placed bitmask
+	for _, c := range u {
+		placed |= l.s.field[c]
+	}
+
+	return extractDomain(placed, l.s.size)
+}
+
+// Empty cells of unit which have any of values as candidate
+func (l *logic) cellsWith(u []int, values bitmask) []int {
+	var res []int
+	for _, c := range u {
+		if l.s.field[c] == 0 && l.cand[c]&values != 0 {
+			res = append(res, c)
+		}
+	}
+
+	return res
+}
+
+// All sets of k indexes from 0 to n-1 in increasing order
+func combinations(n, k int) [][]int {
+	var res [][]int
+	var rec func(start int, curr []int)
+	rec = func(start int, curr []int) {
+		if len(curr) == k {
+			res = append(res, append([]int{}, curr...))
+			return
+		}
+		for i := start; i < n; i++ {
+			rec(i+1, append(curr, i))
+		}
+	}
+	rec(0, nil)
+
+	return res
+}
+
+func contains(cells []int, c int) bool {
+	for _, x := range cells {
+		if x == c {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,111 @@
+package sudoku
+
+import "math/bits"
+
+// Cells sharing row, column or block with every cell, shared by all states of one search
+type peers [][]int
+
+func newPeers(s *Sudoku) peers {
+	size := s.size
+	res := make(peers, size*size)
+	for c := range res {
+		i, j := c/size, c%size
+		start := s.blockStart(s.block(c))
+		for k := 0; k < size; k++ {
+			// Row, column, then block without cells of the same row and column
+			if k != j {
+				res[c] = append(res[c], i*size+k)
+			}
+			if k != i {
+				res[c] = append(res[c], k*size+j)
+			}
+			if p := start + k/s.boxWidth*size + k%s.boxWidth; p/size != i && p%size != j {
+				res[c] = append(res[c], p)
+			}
+		}
+	}
+
+	return res
+}
+
+// Build candidate domains from field and make them arc consistent.
+// Returns number of cells filled by propagation and false on domain wipe-out
+func (s *Sudoku) initDomains() (int, bool) {
+	if s.peers == nil {
+		s.peers = newPeers(s)
+	}
+
+	var mask bitmask = 1<<s.size - 1
+	s.domains = make([]bitmask, len(s.field))
+	var queue []int
+	for c, v := range s.field {
+		if v != 0 {
+			s.domains[c] = v
+		} else {
+			s.domains[c] = ^(s.horizontalConstraint(c) | s.verticalConstraint(c) | s.blockConstraint(c)) & mask
+		}
+		switch bits.OnesCount64(s.domains[c]) {
+		case 0:
+			return 0, false
+		case 1:
+			queue = append(queue, c)
+		}
+	}
+
+	fills := 0
+	for _, c := range queue {
+		if s.field[c] == 0 {
+			s.field[c] = s.domains[c]
+			fills++
+		}
+	}
+	f, ok := s.propagate(queue)
+
+	return fills + f, ok
+}
+
+// Assign value to cell and propagate it.
+// Returns number of cells filled by propagation and false on domain wipe-out
+func (s *Sudoku) assign(c int, v bitmask) (int, bool) {
+	s.field[c] = v
+	s.domains[c] = v
+
+	return s.propagate([]int{c})
+}
+
+// AC-3 over not-equal constraints: only assigned cell can make arc to it inconsistent,
+// so queue holds assigned cells, which values are removed from domains of their peers
+func (s *Sudoku) propagate(queue []int) (int, bool) {
+	fills := 0
+	for len(queue) != 0 {
+		c := queue[0]
+		queue = queue[1:]
+		v := s.domains[c]
+
+		for _, p := range s.peers[c] {
+			if s.domains[p]&v == 0 {
+				continue
+			}
+			s.domains[p] &^= v
+			switch bits.OnesCount64(s.domains[p]) {
+			case 0:
+				return fills, false
+			case 1:
+				s.field[p] = s.domains[p]
+				fills++
+				queue = append(queue, p)
+			}
+		}
+	}
+
+	return fills, true
+}
+
+// Copy of state to be changed independently
+func (s *Sudoku) clone() *Sudoku {
+	res := &Sudoku{size: s.size, boxHeight: s.boxHeight, boxWidth: s.boxWidth, logic: s.logic, peers: s.peers}
+	res.field = append(res.field, s.field...)
+	res.domains = append(res.domains, s.domains...)
+
+	return res
+}
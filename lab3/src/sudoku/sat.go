@@ -0,0 +1,76 @@
+package sudoku
+
+import (
+	"io"
+	"sudoku/sat"
+)
+
+// Variable which is true if cell has value (from 1 to size)
+func (s *Sudoku) satVar(cell, value int) sat.Lit {
+	return sat.Lit(cell*s.size + value)
+}
+
+// Encode sudoku as CNF: every cell has exactly one value,
+// every row, column and block has every value exactly once, givens are unit clauses
+func (s *Sudoku) CNF() *sat.CNF {
+	f := &sat.CNF{Vars: s.size * s.size * s.size}
+
+	// Groups of variables of which exactly one is true
+	exactlyOne := func(lits []sat.Lit) {
+		f.AddClause(lits...)
+		for i := range lits {
+			for j := i + 1; j < len(lits); j++ {
+				f.AddClause(-lits[i], -lits[j])
+			}
+		}
+	}
+
+	for c := range s.field {
+		var lits []sat.Lit
+		for v := 1; v <= s.size; v++ {
+			lits = append(lits, s.satVar(c, v))
+		}
+		exactlyOne(lits)
+	}
+	for _, u := range newLogic(s).units {
+		for v := 1; v <= s.size; v++ {
+			var lits []sat.Lit
+			for _, c := range u {
+				lits = append(lits, s.satVar(c, v))
+			}
+			exactlyOne(lits)
+		}
+	}
+
+	for c, v := range s.field {
+		if v != 0 {
+			f.AddClause(s.satVar(c, getIntFromBinary(v, s.size)))
+		}
+	}
+
+	return f
+}
+
+// Write CNF of sudoku in DIMACS format
+func (s *Sudoku) WriteDIMACS(w io.Writer) error {
+	return s.CNF().WriteDIMACS(w)
+}
+
+// Solve sudoku with SAT solver, nil if it has no solution
+func (s *Sudoku) SolveSAT() *Sudoku {
+	model, _, ok := sat.Solve(s.CNF())
+	if !ok {
+		return nil
+	}
+
+	res := &Sudoku{size: s.size, boxHeight: s.boxHeight, boxWidth: s.boxWidth, field: make([]bitmask, len(s.field))}
+	for c := range res.field {
+		for v := 1; v <= s.size; v++ {
+			if model[s.satVar(c, v)] {
+				res.field[c] = getBinaryFromInt(v, s.size)
+			}
+		}
+	}
+
+	return res
+}
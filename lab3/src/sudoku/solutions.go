@@ -0,0 +1,48 @@
+package sudoku
+
+import "context"
+
+// Find solutions and send them to returned channel as soon as they are found.
+// At most limit solutions are sent, 0 means no limit.
+// Channel is closed when search is over or ctx is done
+func (s *Sudoku) Solutions(ctx context.Context, limit int) <-chan *Sudoku {
+	ch := make(chan *Sudoku)
+
+	go func() {
+		defer close(ch)
+
+		found := 0
+		s.search(func(curr *Sudoku) bool {
+			select {
+			case ch <- curr:
+			case <-ctx.Done():
+				return false
+			}
+			found++
+			return limit == 0 || found < limit
+		}, false)
+	}()
+
+	return ch
+}
+
+// Count solutions, counting stops at limit, 0 means no limit
+func (s *Sudoku) CountSolutions(limit int) int {
+	count, _ := s.countSolutions(limit)
+	return count
+}
+
+func (s *Sudoku) countSolutions(limit int) (int, Stats) {
+	count := 0
+	stats := s.search(func(*Sudoku) bool {
+		count++
+		return limit == 0 || count < limit
+	}, false)
+
+	return count, stats
+}
+
+// Check if sudoku has exactly one solution
+func (s *Sudoku) IsUnique() bool {
+	return s.CountSolutions(2) == 1
+}
@@ -3,45 +3,98 @@ package sudoku
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"math"
+	"math/bits"
 	"os"
 	"strconv"
 )
 
 var startFields = make(map[int]struct{})
 
+// Max size of sudoku, which cells fit into bitmask
+const MaxSize = 64
+
 type Sudoku struct {
-	size    int
-	subSize int
-	field   []uint32
+	size      int
+	boxHeight int
+	boxWidth  int
+	field     []bitmask
+	domains   []bitmask // candidates of cells, kept arc consistent during search
+	peers     peers
+	logic     bool // apply logical techniques after every assignment
+}
+
+func (s *Sudoku) Size() int {
+	return s.size
+}
+
+// Enable logical techniques as propagation step of search
+func (s *Sudoku) SetLogic(logic bool) {
+	s.logic = logic
 }
 
-func NewSudoku(path string) *Sudoku {
+func NewSudoku(path string) (*Sudoku, error) {
 	csvConf, err := os.Open(path)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	defer func() {
-		if err = csvConf.Close(); err != nil {
-			panic(err)
-		}
-	}()
+	defer csvConf.Close()
+
+	return ReadSudoku(csvConf)
+}
 
-	reader := csv.NewReader(csvConf)
+// Read sudoku from CSV: size in the first line, optionally followed by box height and width,
+// then rows of values separated by spaces, 0 for empty cell
+func ReadSudoku(r io.Reader) (*Sudoku, error) {
+	reader := csv.NewReader(r)
 	reader.Comma = ' '
 	reader.TrimLeadingSpace = true
 	reader.FieldsPerRecord = -1
-	data, err := reader.ReadAll()
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, &ParseError{Line: 1, Column: 1, Err: ErrSize}
+	}
+	if err != nil {
+		return nil, err
+	}
+	line, column := reader.FieldPos(0)
+	size, boxHeight, boxWidth, err := parseHeader(header)
 	if err != nil {
-		panic(err)
+		return nil, &ParseError{Line: line, Column: column, Err: err}
 	}
 
-	size, _ := strconv.Atoi(data[0][0])
-	sudoku := &Sudoku{size: size, subSize: int(math.Sqrt(float64(size))), field: make([]uint32, size*size)}
-	data = data[1:]
-	for i, row := range data {
+	sudoku := &Sudoku{size: size, boxHeight: boxHeight, boxWidth: boxWidth, field: make([]bitmask, size*size)}
+	for i := 0; ; i++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			if i != size {
+				return nil, &ParseError{Line: line + 1, Column: 1, Err: ErrRows}
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, column = reader.FieldPos(0)
+		// Skip trailing spaces
+		for len(row) > 1 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		if i == size {
+			return nil, &ParseError{Line: line, Column: column, Err: ErrRows}
+		}
+		if len(row) != size {
+			return nil, &ParseError{Line: line, Column: column, Err: ErrColumns}
+		}
+
 		for j, el := range row {
-			val, _ := strconv.Atoi(el)
+			val, err := strconv.Atoi(el)
+			if err != nil || val < 0 || val > size {
+				line, column = reader.FieldPos(j)
+				return nil, &ParseError{Line: line, Column: column, Err: fmt.Errorf("%w, got %q", ErrValue, el)}
+			}
 			sudoku.field[i*size+j] = getBinaryFromInt(val, size)
 			if val != 0 {
 				startFields[i*size+j] = struct{}{}
@@ -49,88 +102,169 @@ func NewSudoku(path string) *Sudoku {
 		}
 	}
 
-	return sudoku
+	return sudoku, nil
+}
+
+// Size, box height and box width from CSV header. Without box size boxes are square
+func parseHeader(header []string) (int, int, int, error) {
+	// Skip trailing spaces
+	for len(header) > 1 && header[len(header)-1] == "" {
+		header = header[:len(header)-1]
+	}
+	var dims []int
+	for _, el := range header {
+		n, err := strconv.Atoi(el)
+		if err != nil {
+			return 0, 0, 0, ErrSize
+		}
+		dims = append(dims, n)
+	}
+
+	size := dims[0]
+	if size < 1 || size > MaxSize {
+		return 0, 0, 0, ErrSize
+	}
+	switch len(dims) {
+	case 1:
+		box := int(math.Sqrt(float64(size)))
+		if box*box != size {
+			return 0, 0, 0, ErrSize
+		}
+		return size, box, box, nil
+	case 3:
+		if dims[1] < 1 || dims[2] < 1 || dims[1]*dims[2] != size {
+			return 0, 0, 0, ErrBox
+		}
+		return size, dims[1], dims[2], nil
+	}
+
+	return 0, 0, 0, ErrSize
+}
+
+// Write sudoku in the same CSV format ReadSudoku reads
+func (s *Sudoku) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = ' '
+
+	header := []string{strconv.Itoa(s.size)}
+	if s.boxHeight != s.boxWidth {
+		header = append(header, strconv.Itoa(s.boxHeight), strconv.Itoa(s.boxWidth))
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < s.size; i++ {
+		row := make([]string, s.size)
+		for j := range row {
+			row[j] = strconv.Itoa(getIntFromBinary(s.field[i*s.size+j], s.size))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
 }
 
 func (s *Sudoku) Solve() *Sudoku {
+	var solution *Sudoku
+	s.search(func(curr *Sudoku) bool {
+		solution = curr
+		return false
+	}, true)
+
+	return solution
+}
+
+// Statistics of search
+type Stats struct {
+	Opened int // number of opened states
+	Fills  int // number of cells filled by propagation
+}
+
+// DFS over states. Every found solution is passed to visit,
+// search stops when visit returns false or all states are opened
+func (s *Sudoku) search(visit func(*Sudoku) bool, verbose bool) Stats {
 	var stack []*Sudoku
-	var count int
+	var count, fills int
 
-	stack = append(stack, s)
+	root := s.clone()
+	fills, ok := root.initDomains()
+	if !ok {
+		return Stats{Fills: fills}
+	}
+	if root.logic {
+		if _, ok := root.Deduce(); !ok {
+			return Stats{Fills: fills}
+		}
+	}
+	stack = append(stack, root)
 
 	for len(stack) != 0 {
-		fmt.Print("Opened: ", count)
+		if verbose {
+			fmt.Print("Opened: ", count)
+		}
 		curr := stack[len(stack)-1]
 		stack = stack[:len(stack)-1]
 		count++
 
 		if curr.heuristic() == 0 {
-			fmt.Println()
-			return curr
+			if !visit(curr) {
+				break
+			}
+			continue
 		}
 
-		neighbours := curr.getNeighbours()
+		neighbours, f := curr.getNeighbours()
 		stack = append(stack, neighbours...)
-		fmt.Print("\033[1K\r")
+		fills += f
+		if verbose {
+			fmt.Print("\033[1K\r")
+		}
 	}
 
-	fmt.Println()
+	if verbose {
+		fmt.Println()
+	}
 
-	return nil
+	return Stats{Opened: count, Fills: fills}
 }
 
-func (s *Sudoku) getNeighbours() []*Sudoku {
+// Get states with the most constrained variable assigned, and number of cells filled by propagation.
+// States with wiped out domain are pruned
+func (s *Sudoku) getNeighbours() ([]*Sudoku, int) {
 	var neighbourhood []*Sudoku
+	var fills int
 
 	// Get undefined variable with the smallest domain
-	var idx int
-	var smallestDomain []uint32
-	smallestDomainLen := math.MaxInt
-	for i := 0; i < len(s.field); i++ {
-		if v := getIntFromBinary(s.field[i], s.size); v == 0 {
-			d := extractDomain(
-				s.horizontalConstraint(i)|s.verticalConstraint(i)|s.blockConstraint(i),
-				s.size)
-			if len(d) < smallestDomainLen {
-				smallestDomain = d
-				smallestDomainLen = len(smallestDomain)
-				idx = i
-			}
+	idx := -1
+	for i, d := range s.domains {
+		if s.field[i] == 0 && (idx == -1 || bits.OnesCount64(d) < bits.OnesCount64(s.domains[idx])) {
+			idx = i
 		}
 	}
-	domain := smallestDomain
+	if idx == -1 {
+		return nil, 0
+	}
 
-	// Generate neighbours with forward checking
-	for i := 0; i < len(domain); i++ {
-		neighbour := &Sudoku{
-			size:    s.size,
-			subSize: s.subSize,
+	// Generate neighbours with propagation
+	for _, v := range extractDomain(^s.domains[idx], s.size) {
+		neighbour := s.clone()
+		f, ok := neighbour.assign(idx, v)
+		fills += f
+		if !ok {
+			continue
+		}
+		// Prune branch if logic finds contradiction
+		if s.logic {
+			if _, ok := neighbour.Deduce(); !ok {
+				continue
+			}
 		}
-		neighbour.field = append(neighbour.field, s.field...)
-		neighbour.field[idx] = domain[i]
-
-		neighbour.forwardCheck()
 
 		neighbourhood = append(neighbourhood, neighbour)
 	}
 
-	/*sort.Slice(neighbourhood, func(i, j int) bool {
-		return neighbourhood[i].heuristic() < neighbourhood[j].heuristic()
-	})*/
-
-	return neighbourhood
-}
-
-func (s *Sudoku) forwardCheck() {
-	for i := 0; i < len(s.field); i++ {
-		if v := getIntFromBinary(s.field[i], s.size); v == 0 {
-			domain := extractDomain(
-				s.horizontalConstraint(i)|s.verticalConstraint(i)|s.blockConstraint(i),
-				s.size)
-			if len(domain) == 1 {
-				s.field[i] = domain[0]
-				i = 0
-			}
-		}
-	}
+	return neighbourhood, fills
 }
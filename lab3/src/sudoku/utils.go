@@ -1,32 +1,35 @@
 package sudoku
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 func (s *Sudoku) PrintSudoku(isUnsolved bool) {
+	// Cells are widened for multi-digit values
+	width := len(strconv.Itoa(s.size))
+	if width < 2 {
+		width = 2
+	}
+	line := " " + strings.Repeat("-", s.size*(width+1)+2*(s.size/s.boxWidth)+1)
+
 	for i := 0; i < s.size; i++ {
-		if i%s.subSize == 0 {
-			fmt.Print(" ")
-			for k := 0; k < (s.size+1)*3+s.subSize+1; k++ {
-				fmt.Printf("-")
-			}
-			fmt.Println()
+		if i%s.boxHeight == 0 {
+			fmt.Println(line)
 		}
 		for j := 0; j < s.size; j++ {
-			if j%s.subSize == 0 {
+			if j%s.boxWidth == 0 {
 				fmt.Print(" |")
 			}
 			n := getIntFromBinary(s.field[i*s.size+j], s.size)
 			if _, isStatic := startFields[i*s.size+j]; isStatic {
 				fmt.Print("\033[32m") // green
 			}
-			if isUnsolved {
-				if n == 0 {
-					fmt.Printf("  *")
-				} else {
-					fmt.Printf(" %2d", n)
-				}
+			if isUnsolved && n == 0 {
+				fmt.Printf(" %*s", width, "*")
 			} else {
-				fmt.Printf(" %2d", n)
+				fmt.Printf(" %*d", width, n)
 			}
 			if _, isStatic := startFields[i*s.size+j]; isStatic {
 				fmt.Print("\033[0m") // green
@@ -35,15 +38,14 @@ func (s *Sudoku) PrintSudoku(isUnsolved bool) {
 		fmt.Print(" |")
 		fmt.Print("\n")
 	}
-	fmt.Print(" ")
-	for k := 0; k < (s.size+1)*3+s.subSize+1; k++ {
-		fmt.Printf("-")
-	}
-	fmt.Println()
+	fmt.Println(line)
 }
 
-func extractDomain(bin uint32, max int) []uint32 {
-	var res []uint32
+// One-hot value of cell or set of values, value n is bit n-1
+type bitmask = uint64
+
+func extractDomain(bin bitmask, max int) []bitmask {
+	var res []bitmask
 
 	for i := 0; i < max; i++ {
 		isFilled := bin & 1
@@ -57,7 +59,7 @@ func extractDomain(bin uint32, max int) []uint32 {
 }
 
 // Functions to work with binary
-func getIntFromBinary(b uint32, max int) int {
+func getIntFromBinary(b bitmask, max int) int {
 	var res int
 	for b != 0 {
 		res++
@@ -67,12 +69,12 @@ func getIntFromBinary(b uint32, max int) int {
 	return res
 }
 
-func getBinaryFromInt(n int, max int) uint32 {
+func getBinaryFromInt(n int, max int) bitmask {
 	if n == 0 {
 		return 0
 	}
 
-	var res uint32 = 1
+	var res bitmask = 1
 	for i := 0; i < n-1; i++ {
 		res <<= 1
 	}
@@ -80,7 +82,7 @@ func getBinaryFromInt(n int, max int) uint32 {
 	return res
 }
 
-func countZeros(b uint32, max int) int {
+func countZeros(b bitmask, max int) int {
 	var res int
 
 	for i := 0; i < max; i++ {
@@ -0,0 +1,89 @@
+package sudoku
+
+import "fmt"
+
+type Problem int
+
+const (
+	OutOfRange Problem = iota
+	DuplicateInRow
+	DuplicateInColumn
+	DuplicateInBlock
+	EmptyDomain
+)
+
+func (p Problem) String() string {
+	switch p {
+	case OutOfRange:
+		return "value is out of range"
+	case DuplicateInRow:
+		return "value is repeated in row"
+	case DuplicateInColumn:
+		return "value is repeated in column"
+	case DuplicateInBlock:
+		return "value is repeated in block"
+	case EmptyDomain:
+		return "no value can be placed"
+	}
+
+	return "unknown problem"
+}
+
+// Problem found in cell, row and column are numbered from 1
+type Diagnostic struct {
+	Row     int
+	Column  int
+	Value   int
+	Problem Problem
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("row %d, column %d, value %d: %s", d.Row, d.Column, d.Value, d.Problem)
+}
+
+// Check givens for values out of range and repetitions, and empty cells for empty domains.
+// Every conflicting cell is reported, nil is returned for valid sudoku
+func (s *Sudoku) Validate() []Diagnostic {
+	var res []Diagnostic
+
+	// Work on copy, cells are cleared while checked
+	c := &Sudoku{size: s.size, boxHeight: s.boxHeight, boxWidth: s.boxWidth}
+	c.field = append(c.field, s.field...)
+
+	var mask bitmask = 1<<s.size - 1
+	for i, v := range s.field {
+		d := Diagnostic{Row: i/s.size + 1, Column: i%s.size + 1, Value: getIntFromBinary(v, s.size)}
+
+		if v&^mask != 0 || v&(v-1) != 0 {
+			d.Problem = OutOfRange
+			res = append(res, d)
+			continue
+		}
+
+		if v == 0 {
+			if len(extractDomain(c.horizontalConstraint(i)|c.verticalConstraint(i)|c.blockConstraint(i), s.size)) == 0 {
+				d.Problem = EmptyDomain
+				res = append(res, d)
+			}
+			continue
+		}
+
+		c.field[i] = 0
+		for _, check := range []struct {
+			constraint func(int) bitmask
+			problem    Problem
+		}{
+			{c.horizontalConstraint, DuplicateInRow},
+			{c.verticalConstraint, DuplicateInColumn},
+			{c.blockConstraint, DuplicateInBlock},
+		} {
+			if check.constraint(i)&v != 0 {
+				d.Problem = check.problem
+				res = append(res, d)
+			}
+		}
+		c.field[i] = v
+	}
+
+	return res
+}